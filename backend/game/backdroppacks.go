@@ -0,0 +1,283 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	mrand "math/rand"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Id of the backdrop pack that is built into the frontend.
+const DEFAULT_BACKDROP_PACK = "default"
+
+// Additional backdrop packs are served below this path.
+const BACKDROP_PACKS_URL = "/backdrop-packs/"
+
+// Backdrops that fit the prompt packs of the session are this much more
+// likely to be selected.
+const backdropCategoryWeight = 2
+
+// What the built-in backdrops show.
+var defaultBackdropThemes = map[Backdrop][]string{
+	BACKDROP_ARCTIC:         {"cold", "nature"},
+	BACKDROP_GRAVEYARD:      {"spooky", "night"},
+	BACKDROP_PIRATE_SHIP:    {"sea", "adventure"},
+	BACKDROP_THEATER_STAGE1: {"stage", "indoor"},
+	BACKDROP_DESERT:         {"hot", "nature"},
+}
+
+// Prompt pack categories the built-in backdrops fit best.
+var defaultBackdropCategories = map[Backdrop][]string{
+	BACKDROP_ARCTIC:         {"nature", "animals"},
+	BACKDROP_GRAVEYARD:      {"horror", "fantasy"},
+	BACKDROP_PIRATE_SHIP:    {"adventure", "fantasy"},
+	BACKDROP_THEATER_STAGE1: {"mixed", "people"},
+	BACKDROP_DESERT:         {"nature", "adventure"},
+}
+
+// A set of backdrops with some metadata. Additional packs are loaded from
+// the -backdrop-packs directory, one subdirectory per pack with the images
+// and a pack.json:
+//
+//	{
+//	  "name": "Outer Space",
+//	  "backdrops": [
+//	    {
+//	      "file": "moon.png",
+//	      "name": "Moon Base",
+//	      "themes": ["space", "night"],
+//	      "categories": ["sci-fi"]
+//	    },
+//	    ...
+//	  ]
+//	}
+//
+// The id of the pack is the name of the directory. The images should have
+// the size of the painting canvas.
+type BackdropPack struct {
+	Id        string              `json:"-"`
+	Name      string              `json:"name"`
+	Backdrops []backdropPackEntry `json:"backdrops"`
+
+	dir   string // empty for the built-in pack
+	infos []BackdropInfo
+}
+
+type backdropPackEntry struct {
+	File       string   `json:"file"`
+	Name       string   `json:"name"`
+	Themes     []string `json:"themes"`
+	Categories []string `json:"categories"`
+}
+
+// All available backdrop packs by id.
+var backdropPacks = map[string]*BackdropPack{
+	DEFAULT_BACKDROP_PACK: createDefaultBackdropPack(),
+}
+
+// All backdrops of all packs by their id.
+var backdropIndex = indexBackdrops(backdropPacks[DEFAULT_BACKDROP_PACK])
+
+func createDefaultBackdropPack() *BackdropPack {
+	pack := &BackdropPack{
+		Id:   DEFAULT_BACKDROP_PACK,
+		Name: "Crayos",
+	}
+	for _, backdrop := range ALL_BACKDROP_ITEMS {
+		pack.infos = append(pack.infos, BackdropInfo{
+			Id:         string(backdrop),
+			Name:       packItemName(string(backdrop)),
+			Url:        "img/" + string(backdrop) + ".png",
+			Themes:     defaultBackdropThemes[backdrop],
+			Categories: defaultBackdropCategories[backdrop],
+		})
+	}
+	return pack
+}
+
+func indexBackdrops(pack *BackdropPack) map[string]*BackdropInfo {
+	index := make(map[string]*BackdropInfo)
+	for i := range pack.infos {
+		index[pack.infos[i].Id] = &pack.infos[i]
+	}
+	return index
+}
+
+func loadBackdropPack(dir string) (*BackdropPack, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "pack.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	pack := &BackdropPack{
+		Id:  filepath.Base(dir),
+		dir: dir,
+	}
+	err = json.Unmarshal(data, pack)
+	if err != nil {
+		return nil, err
+	}
+
+	if pack.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	seen := make(map[string]bool)
+	for _, backdrop := range pack.Backdrops {
+		if err := checkPackImage(dir, backdrop.File); err != nil {
+			return nil, err
+		}
+		if seen[backdrop.File] {
+			return nil, fmt.Errorf("duplicate backdrop file %q", backdrop.File)
+		}
+		seen[backdrop.File] = true
+
+		name := backdrop.Name
+		if name == "" {
+			name = packItemName(strings.TrimSuffix(backdrop.File, filepath.Ext(backdrop.File)))
+		}
+
+		info := BackdropInfo{
+			Id:         pack.Id + "/" + backdrop.File,
+			Name:       name,
+			Url:        path.Join(BACKDROP_PACKS_URL, pack.Id, backdrop.File),
+			Themes:     backdrop.Themes,
+			Categories: backdrop.Categories,
+		}
+		if info.Themes == nil {
+			info.Themes = []string{}
+		}
+		if info.Categories == nil {
+			info.Categories = []string{}
+		}
+		pack.infos = append(pack.infos, info)
+	}
+
+	if len(pack.infos) == 0 {
+		return nil, fmt.Errorf("at least one backdrop is required")
+	}
+
+	return pack, nil
+}
+
+// Loads all backdrop packs from the subdirectories of the directory.
+func loadBackdropPacks(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		pack_dir := filepath.Join(dir, entry.Name())
+		pack, err := loadBackdropPack(pack_dir)
+		if err != nil {
+			return fmt.Errorf("%s: %w", pack_dir, err)
+		}
+		if _, ok := backdropPacks[pack.Id]; ok {
+			return fmt.Errorf("%s: duplicate backdrop pack id %q", pack_dir, pack.Id)
+		}
+
+		backdropPacks[pack.Id] = pack
+		for id, info := range indexBackdrops(pack) {
+			backdropIndex[id] = info
+		}
+		log.Println("Loaded backdrop pack", pack.Id, "with", len(pack.infos), "backdrops")
+	}
+	return nil
+}
+
+// Lists the available backdrop packs sorted by id.
+func listBackdropPacks() []BackdropPackInfo {
+	infos := make([]BackdropPackInfo, 0, len(backdropPacks))
+	for _, pack := range backdropPacks {
+		infos = append(infos, BackdropPackInfo{
+			Id:        pack.Id,
+			Name:      pack.Name,
+			Backdrops: pack.infos,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Id < infos[j].Id
+	})
+	return infos
+}
+
+// Returns the file of a backdrop of a loaded pack, so it can be served.
+// The built-in backdrops are part of the frontend and aren't found here.
+func BackdropFile(pack_id string, file string) (string, bool) {
+	pack, ok := backdropPacks[pack_id]
+	if !ok || pack.dir == "" {
+		return "", false
+	}
+	if _, ok := backdropIndex[pack_id+"/"+file]; !ok {
+		return "", false
+	}
+	return filepath.Join(pack.dir, file), true
+}
+
+// Selects up to `count` backdrops of the selected packs. Backdrops aren't
+// repeated within a match until all of them were `used`, so there may be
+// fewer. Backdrops that fit the categories of the selected prompt packs are
+// preferred.
+func (session *Session) selectBackdrops(rng *mrand.Rand, count int, used map[string]bool) []string {
+	categories := make(map[string]bool)
+	for _, id := range session.Settings.PromptPacks {
+		categories[promptPacks[id].Category] = true
+	}
+
+	pool := make([]*BackdropInfo, 0)
+	collect := func() {
+		for _, id := range session.Settings.BackdropPacks {
+			for i, info := range backdropPacks[id].infos {
+				if !used[info.Id] {
+					pool = append(pool, &backdropPacks[id].infos[i])
+				}
+			}
+		}
+	}
+	collect()
+	if len(pool) == 0 {
+		session.ServerPrint("All backdrops were used, starting over")
+		for id := range used {
+			delete(used, id)
+		}
+		collect()
+	}
+
+	weight := func(info *BackdropInfo) int {
+		for _, category := range info.Categories {
+			if categories[category] {
+				return backdropCategoryWeight
+			}
+		}
+		return 1
+	}
+
+	// Weighted draw without putting the backdrops back:
+	selected := make([]string, 0, count)
+	for len(selected) < count && len(pool) > 0 {
+		total := 0
+		for _, info := range pool {
+			total += weight(info)
+		}
+		pick := rng.Intn(total)
+		for i, info := range pool {
+			pick -= weight(info)
+			if pick < 0 {
+				selected = append(selected, info.Id)
+				pool = append(pool[:i], pool[i+1:]...)
+				break
+			}
+		}
+	}
+	return selected
+}
@@ -0,0 +1,459 @@
+package game
+
+import (
+	"log"
+	"math"
+	mrand "math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// How often a bot checks if it has something to do.
+	botTickInterval = 250 * time.Millisecond
+
+	// Bots think a bit before they act, so they feel like players.
+	botMinDelay = 1 * time.Second
+	botMaxDelay = 4 * time.Second
+
+	// A bot adds a stroke to its painting this often.
+	botStrokeInterval = 1500 * time.Millisecond
+)
+
+// Names the bots pick from, a taken name gets a number.
+var BOT_NAMES = []string{
+	"Bot Picasso",
+	"Bot Frida",
+	"Bot Dali",
+	"Bot Monet",
+	"Bot Banksy",
+	"Bot Hokusai",
+	"Bot Vermeer",
+	"Bot Klimt",
+}
+
+// Colors a bot paints with, taken from the frontend's palette.
+var BOT_COLORS = []string{
+	"#e42932",
+	"#ff8652",
+	"#552cb7",
+	"#00995e",
+	"#058cd7",
+	"#000",
+}
+
+// Bots are players without a client. Their transport doesn't connect to
+// anything, the bot itself reads the outbound messages of its player.
+type botTransport struct {
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func (transport *botTransport) Close() {
+	transport.closeOnce.Do(func() {
+		close(transport.done)
+	})
+}
+
+// Checks if the player is played by the server.
+func (player *Player) IsBot() bool {
+	_, ok := player.transport.(*botTransport)
+	return ok
+}
+
+type botPoint struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+// Same format as the paths of the frontend's painter.
+type botPath struct {
+	Color  string     `json:"color"`
+	Points []botPoint `json:"points"`
+}
+
+type botAction struct {
+	at  time.Time
+	run func()
+}
+
+type bot struct {
+	player    *Player
+	transport *botTransport
+	rng       *mrand.Rand
+
+	view    GameView
+	actions []botAction // things to do once their time has come
+
+	// The painting while the bot is the painter:
+	painting   bool
+	paths      []botPath
+	nextStroke time.Time
+}
+
+// Creates a bot player. The bot plays until the player is closed or it's
+// told to leave via its transport.
+func createBot(nick string) *Player {
+	transport := &botTransport{
+		done: make(chan struct{}),
+	}
+	player := CreatePlayer(transport)
+	player.NickName = nick
+
+	b := &bot{
+		player:    player,
+		transport: transport,
+		rng:       mrand.New(mrand.NewSource(time.Now().UnixNano())),
+	}
+	go b.run()
+
+	return player
+}
+
+func (b *bot) run() {
+	defer b.player.Close()
+
+	ticker := time.NewTicker(botTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.transport.done:
+			return
+
+		case <-b.player.OutboundSignal():
+			messages, ok := b.player.TakeOutbound()
+			if !ok {
+				return
+			}
+			for _, data := range messages {
+				msg, err := DeserializeMessage(data)
+				if err != nil {
+					log.Println("bot ", b.player.NickName, " can't read message: ", err)
+					continue
+				}
+				b.handleMessage(msg)
+			}
+
+		case now := <-ticker.C:
+			b.tick(now)
+		}
+	}
+}
+
+// Sends a command to the session, like a client would.
+func (b *bot) send(msg Message) {
+	data, err := SerializeMessage(msg)
+	if err != nil {
+		log.Println("bot ", b.player.NickName, " can't write message: ", err)
+		return
+	}
+	if err := b.player.HandleInbound(data); err != nil {
+		log.Println("bot ", b.player.NickName, ": ", err)
+	}
+}
+
+// Runs the action after a short, random delay.
+func (b *bot) later(run func()) {
+	delay := botMinDelay + time.Duration(b.rng.Int63n(int64(botMaxDelay-botMinDelay)))
+	b.actions = append(b.actions, botAction{
+		at:  time.Now().Add(delay),
+		run: run,
+	})
+}
+
+func (b *bot) tick(now time.Time) {
+	due := b.actions
+	b.actions = nil
+	for _, action := range due {
+		if now.Before(action.at) {
+			b.actions = append(b.actions, action)
+		} else {
+			action.run()
+		}
+	}
+
+	if b.painting && !now.Before(b.nextStroke) {
+		b.paintStroke()
+		b.nextStroke = now.Add(botStrokeInterval)
+	}
+}
+
+func (b *bot) pick(options []string) string {
+	return options[b.rng.Intn(len(options))]
+}
+
+func (b *bot) handleMessage(msg Message) {
+	switch msg := msg.(type) {
+	case *ChangeGameViewEvent:
+		b.handleView(msg)
+
+	case *PlayerReadyChangedEvent:
+		// Bots are always ready, even after the host changed the settings:
+		for _, info := range msg.Players {
+			if info.Id == b.player.Id && !info.Ready && b.view == GAME_VIEW_LOBBY {
+				b.send(&UserCommand{
+					Action: USER_ACTION_SET_READY,
+				})
+			}
+		}
+	}
+}
+
+func (b *bot) handleView(msg *ChangeGameViewEvent) {
+	b.view = msg.View
+	b.actions = nil // whatever the bot wanted to do is too late now
+
+	painting := msg.View == GAME_VIEW_ARTSTUDIO_ACTIVE
+	if painting && !b.painting {
+		b.paths = []botPath{}
+		b.nextStroke = time.Now()
+	}
+	b.painting = painting
+
+	if len(msg.BackdropOptions) > 0 {
+		backdrop := b.pick(msg.BackdropOptions)
+		b.later(func() {
+			b.send(&VoteBackdropCommand{
+				Backdrop: backdrop,
+			})
+		})
+	}
+
+	options := msg.VoteOptions
+
+	switch {
+	case msg.View == GAME_VIEW_GALLERY:
+		b.later(func() {
+			b.send(&UserCommand{
+				Action: USER_ACTION_LEAVE_GALLERY,
+			})
+		})
+
+	case len(options) == 0:
+		// nothing to decide
+
+	case msg.View == GAME_VIEW_ARTSTUDIO_STICKER:
+		sticker := b.pick(options)
+		x, y := b.stickerPosition(msg.Painting)
+		b.later(func() {
+			b.send(&PlaceStickerCommand{
+				Sticker:  sticker,
+				X:        x,
+				Y:        y,
+				Scale:    LIMIT_MIN_STICKER_SCALE + b.rng.Float32()*(LIMIT_MAX_STICKER_SCALE-LIMIT_MIN_STICKER_SCALE),
+				Rotation: float32(b.rng.Intn(61) - 30),
+				Flip:     b.rng.Intn(2) == 0,
+			})
+		})
+
+	case options[0] == "star1":
+		stars := b.rate(msg.Painting)
+		b.later(func() {
+			b.send(&VoteCommand{
+				Option: options[stars-1],
+			})
+		})
+
+	case options[len(options)-1] == "continue":
+		stickers := msg.Painting.Stickers
+		b.later(func() {
+			// Vote for a random sticker of someone else:
+			for _, i := range b.rng.Perm(len(stickers)) {
+				if stickers[i].Author != b.player.Id {
+					b.send(&VoteStickerCommand{
+						Sticker: i,
+					})
+					break
+				}
+			}
+			b.send(&VoteCommand{
+				Option: "continue",
+			})
+		})
+
+	default:
+		// Prompts and troll effects are chosen at random:
+		option := b.pick(options)
+		b.later(func() {
+			b.send(&VoteCommand{
+				Option: option,
+			})
+		})
+	}
+}
+
+// Rates paintings by the effort that went into them, with a bit of mood.
+func (b *bot) rate(painting Painting) int {
+	strokes := 0
+	if graphics, ok := painting.Graphics.(map[string]interface{}); ok {
+		if paths, ok := graphics["paths"].([]interface{}); ok {
+			strokes = len(paths)
+		}
+	}
+
+	stars := 2 + strokes/4 + b.rng.Intn(3) - 1
+	if stars < 1 {
+		stars = 1
+	}
+	if stars > 5 {
+		stars = 5
+	}
+	return stars
+}
+
+// Places stickers right on the painted strokes, or somewhere in the middle
+// of an empty canvas.
+func (b *bot) stickerPosition(painting Painting) (float32, float32) {
+	points := make([]map[string]interface{}, 0)
+	if graphics, ok := painting.Graphics.(map[string]interface{}); ok {
+		paths, _ := graphics["paths"].([]interface{})
+		for _, path := range paths {
+			fields, _ := path.(map[string]interface{})
+			path_points, _ := fields["points"].([]interface{})
+			for _, point := range path_points {
+				if coords, ok := point.(map[string]interface{}); ok {
+					points = append(points, coords)
+				}
+			}
+		}
+	}
+
+	jitter := func() float32 {
+		return float32(b.rng.Intn(81) - 40)
+	}
+
+	if len(points) > 0 {
+		point := points[b.rng.Intn(len(points))]
+		x, _ := point["x"].(float64)
+		y, _ := point["y"].(float64)
+		return clampCoordinate(float32(x)+jitter(), LIMIT_CANVAS_WIDTH), clampCoordinate(float32(y)+jitter(), LIMIT_CANVAS_HEIGHT)
+	}
+
+	return float32(LIMIT_CANVAS_WIDTH/4 + b.rng.Intn(LIMIT_CANVAS_WIDTH/2)), float32(LIMIT_CANVAS_HEIGHT/4 + b.rng.Intn(LIMIT_CANVAS_HEIGHT/2))
+}
+
+func clampCoordinate(value float32, size int) float32 {
+	if value < 0 {
+		return 0
+	}
+	if value > float32(size) {
+		return float32(size)
+	}
+	return value
+}
+
+// Adds a simple shape to the painting and shows it to everyone.
+func (b *bot) paintStroke() {
+	center := botPoint{
+		X: float32(150 + b.rng.Intn(LIMIT_CANVAS_WIDTH-300)),
+		Y: float32(150 + b.rng.Intn(LIMIT_CANVAS_HEIGHT-300)),
+	}
+	size := float32(40 + b.rng.Intn(110))
+
+	points := make([]botPoint, 0, 24)
+	switch b.rng.Intn(3) {
+	case 0: // circle
+		for i := 0; i <= 20; i++ {
+			angle := 2 * math.Pi * float64(i) / 20
+			points = append(points, botPoint{
+				X: center.X + size*float32(math.Cos(angle)),
+				Y: center.Y + size*float32(math.Sin(angle)),
+			})
+		}
+
+	case 1: // zigzag
+		for i := 0; i <= 6; i++ {
+			offset := size / 2
+			if i%2 == 1 {
+				offset = -offset
+			}
+			points = append(points, botPoint{
+				X: center.X - size + size*float32(i)/3,
+				Y: center.Y + offset,
+			})
+		}
+
+	default: // box
+		corners := []botPoint{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}, {-1, -1}}
+		for _, corner := range corners {
+			points = append(points, botPoint{
+				X: center.X + size*corner.X,
+				Y: center.Y + size*corner.Y,
+			})
+		}
+	}
+
+	b.paths = append(b.paths, botPath{
+		Color:  BOT_COLORS[b.rng.Intn(len(BOT_COLORS))],
+		Points: points,
+	})
+
+	last := points[len(points)-1]
+	b.send(&SetPaintingCommand{
+		Graphics: map[string]interface{}{
+			"paths": b.paths,
+			"mx":    last.X,
+			"my":    last.Y,
+		},
+	})
+}
+
+// Lets the host add a bot to the lobby.
+func (session *Session) handleAddBot(pmsg PlayerMessage) {
+	if pmsg.Player != session.HostPlayer {
+		session.ServerPrint("Player ", pmsg.Player.NickName, " tried to add a bot. BAD BOY!")
+		return
+	}
+
+	nick := BOT_NAMES[0]
+	for _, name := range BOT_NAMES {
+		if !session.isNickNameTaken(name) {
+			nick = name
+			break
+		}
+	}
+
+	result := make(chan string, 1)
+	player := createBot(nick)
+	session.AddPlayer(JoinRequest{
+		Player:     player,
+		JoinSecret: session.joinSecret,
+		Password:   session.password,
+		Result:     result,
+	})
+
+	if reason := <-result; reason != "" {
+		player.transport.Close()
+		pmsg.Player.Send(&PopUpEvent{
+			Message:  reason,
+			Duration: TIME_POPUP_DURATION_MS,
+		})
+	}
+}
+
+// Lets the host send a bot away. The bot leaves like any other player.
+func (session *Session) handleRemoveBot(pmsg PlayerMessage, msg *RemoveBotCommand) {
+	if pmsg.Player != session.HostPlayer {
+		session.ServerPrint("Player ", pmsg.Player.NickName, " tried to remove a bot. BAD BOY!")
+		return
+	}
+
+	for _, player := range session.Players {
+		if player.Id == msg.Player && player.IsBot() {
+			player.transport.Close()
+			return
+		}
+	}
+	session.ServerPrint("Player ", pmsg.Player.NickName, " tried to remove someone that isn't a bot. BAD BOY!")
+}
+
+// Checks if anyone but bots is left in the session.
+func (session *Session) hasHumans() bool {
+	for _, player := range session.Players {
+		if !player.IsBot() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package game
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// What players may say in the current phase of the session.
+type chatRules struct {
+	spoilers   []string         // texts that must not be leaked to the uninformed players
+	uninformed map[*Player]bool // players that don't know the spoilers
+	reactions  bool             // quick reactions are allowed
+}
+
+// Filters chat messages before they are sent to the other players. Returns
+// the cleaned message.
+type ChatFilter func(message string) string
+
+var chatFilter ChatFilter = censorBlockedWords
+
+// Replaces the default filter, which censors the words of the -chat-blocklist
+// file.
+func SetChatFilter(filter ChatFilter) {
+	chatFilter = filter
+}
+
+// Skeletons of the words that are censored by the default chat filter.
+var chatBlockedWords = map[string]bool{}
+
+func loadChatBlocklist(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		chatBlockedWords[nickNameSkeleton(word)] = true
+	}
+	return scanner.Err()
+}
+
+func censorBlockedWords(message string) string {
+	if len(chatBlockedWords) == 0 {
+		return message
+	}
+
+	words := strings.FieldsFunc(message, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !strings.ContainsRune("!@$|", r)
+	})
+	for _, word := range words {
+		if chatBlockedWords[nickNameSkeleton(word)] {
+			message = strings.ReplaceAll(message, word, strings.Repeat("*", len(splitGraphemes(word))))
+		}
+	}
+	return message
+}
+
+// Limits how fast a player may chat. Allows bursts of LIMIT_CHAT_BURST
+// messages and one more message every TIME_CHAT_MESSAGE_INTERVAL.
+type chatBudget struct {
+	messages float64
+	updated  time.Time
+}
+
+func (budget *chatBudget) take(now time.Time) bool {
+	refill := float64(now.Sub(budget.updated)) / float64(TIME_CHAT_MESSAGE_INTERVAL)
+	budget.messages += refill
+	if budget.messages > float64(LIMIT_CHAT_BURST) {
+		budget.messages = float64(LIMIT_CHAT_BURST)
+	}
+	budget.updated = now
+
+	if budget.messages < 1 {
+		return false
+	}
+	budget.messages -= 1
+	return true
+}
+
+// Replaces the chat rules for the next phase of the session.
+func (session *Session) setChatRules(rules chatRules) {
+	session.chatRules = rules
+}
+
+// Checks if the message would tell an uninformed player something they
+// shouldn't know. Spelling tricks don't help, the message is compared by
+// its skeleton.
+func (session *Session) isSpoiler(sender *Player, message string) bool {
+	if session.chatRules.uninformed[sender] {
+		return false
+	}
+
+	skeleton := nickNameSkeleton(message)
+	for _, spoiler := range session.chatRules.spoilers {
+		spoiler_skeleton := nickNameSkeleton(spoiler)
+		if spoiler_skeleton != "" && strings.Contains(skeleton, spoiler_skeleton) {
+			return true
+		}
+	}
+	return false
+}
+
+func (session *Session) checkChatBudget(player *Player) bool {
+	budget, ok := session.chatBudgets[player]
+	if !ok {
+		budget = &chatBudget{
+			messages: float64(LIMIT_CHAT_BURST),
+			updated:  time.Now(),
+		}
+		session.chatBudgets[player] = budget
+	}
+
+	if !budget.take(time.Now()) {
+		player.Send(&PopUpEvent{
+			Message:  TEXT_POPUP_CHAT_TOO_FAST,
+			Duration: TIME_POPUP_DURATION_MS,
+		})
+		return false
+	}
+	return true
+}
+
+// Removes invisible characters and line breaks from a chat message.
+func cleanChatMessage(raw string) string {
+	message := strings.Map(func(r rune) rune {
+		if r == zeroWidthJoiner || isEmojiTag(r) {
+			return r
+		}
+		if unicode.In(r, unicode.Cc, unicode.Cf, unicode.Co, unicode.Cs) && !unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, norm.NFC.String(raw))
+
+	return strings.Join(strings.Fields(message), " ")
+}
+
+func (session *Session) handleChat(pmsg PlayerMessage, msg *ChatCommand) {
+	message := cleanChatMessage(msg.Message)
+	if message == "" {
+		return
+	}
+	if len(splitGraphemes(message)) > LIMIT_MAX_CHAT_MESSAGE_LEN {
+		message = truncateNickName(message, LIMIT_MAX_CHAT_MESSAGE_LEN)
+	}
+
+	if !session.checkChatBudget(pmsg.Player) {
+		return
+	}
+
+	if session.isSpoiler(pmsg.Player, message) {
+		session.ServerPrint("Player ", pmsg.Player.NickName, " tried to spoil the prompt. BAD BOY!")
+		pmsg.Player.Send(&PopUpEvent{
+			Message:  TEXT_POPUP_CHAT_SPOILER,
+			Duration: TIME_POPUP_DURATION_MS,
+		})
+		return
+	}
+
+	session.Broadcast(&ChatMessageEvent{
+		Sender:  session.playerInfo(pmsg.Player),
+		Message: chatFilter(message),
+	})
+}
+
+func (session *Session) handleReact(pmsg PlayerMessage, msg *ReactCommand) {
+	if !session.chatRules.reactions {
+		session.ServerPrint("Player ", pmsg.Player.NickName, " reacted outside of the showcase. BAD BOY!")
+		return
+	}
+
+	valid := false
+	for _, reaction := range ALL_REACTION_ITEMS {
+		if msg.Reaction == reaction {
+			valid = true
+		}
+	}
+	if !valid {
+		session.ServerPrint("Player ", pmsg.Player.NickName, " sent an unknown reaction. BAD BOY!")
+		return
+	}
+
+	if !session.checkChatBudget(pmsg.Player) {
+		return
+	}
+
+	session.Broadcast(&ReactionEvent{
+		Sender:   session.playerInfo(pmsg.Player),
+		Reaction: msg.Reaction,
+	})
+}
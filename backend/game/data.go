@@ -1,14 +1,50 @@
 package game
 
 import (
-	_ "embed"
 	"strings"
 	"time"
 )
 
 const (
-	LIMIT_MAX_PLAYERS      int = 4  // Maximum number of players per session
-	LIMIT_MAX_NICKNAME_LEN int = 20 // Maximum number of "chars" in the player name
+	LIMIT_MAX_PLAYERS      int = 16 // Maximum number of players per session
+	LIMIT_MAX_NICKNAME_LEN int = 20 // Maximum number of user perceived characters in the player name
+	LIMIT_MAX_PASSWORD_LEN int = 64 // Maximum number of bytes in a session password
+
+	LIMIT_MAX_CHAT_MESSAGE_LEN int = 200 // Maximum number of user perceived characters in a chat message
+	LIMIT_CHAT_BURST           int = 5   // Number of chat messages a player may send in quick succession
+
+	LIMIT_PROMPT_OPTIONS int = 3  // Number of prompts offered for each painting
+	LIMIT_EFFECT_OPTIONS int = 3  // Number of effects offered to a troll
+	LIMIT_CUSTOM_PROMPTS int = 3  // Number of custom prompts each player may submit
+	LIMIT_MIN_PROMPT_LEN int = 3  // Minimum number of user perceived characters in a custom prompt
+	LIMIT_MAX_PROMPT_LEN int = 80 // Maximum number of user perceived characters in a custom prompt
+
+	LIMIT_MIN_PAINTING_TIME_S int = 10  // Shortest painting round the host may choose
+	LIMIT_MAX_PAINTING_TIME_S int = 300 // Longest painting round the host may choose
+
+	LIMIT_TROLLS_PER_SLICE      int = 3 // Most trolls that may use an effect in the same time slice
+	LIMIT_STICKERS_PER_PAINTING int = 6 // Most trolls that may place a sticker on the same painting
+	LIMIT_RATED_PAINTINGS       int = 8 // Most paintings that are rated at the end of a match
+
+	LIMIT_BACKDROP_OPTIONS int = 3 // Number of backdrops the trolls may vote for
+
+	LIMIT_STICKER_OPTIONS        int = 5 // Number of stickers offered to a troll
+	LIMIT_MAX_STICKERS_PER_TROLL int = 5 // Most stickers a troll may place on the same painting
+
+	LIMIT_MIN_STICKER_SCALE float32 = 0.5 // Smallest a sticker may be scaled
+	LIMIT_MAX_STICKER_SCALE float32 = 2   // Largest a sticker may be scaled
+
+	LIMIT_CANVAS_WIDTH  int = 1385 // Width of the painting canvas in the frontend
+	LIMIT_CANVAS_HEIGHT int = 737  // Height of the painting canvas in the frontend
+
+	LIMIT_MAX_PAINT_TURNS int = 5  // Most times each player may paint in a match with rotating painters
+	LIMIT_MAX_ROUNDS      int = 20 // Most paintings in a match with random painters
+
+	POINTS_GUESS_MAX     int = 5 // Points for guessing the prompt right at the start of the round
+	POINTS_GUESS_PAINTER int = 1 // Points for the painter for each troll that guessed the prompt
+
+	POINTS_EFFECT_USED  float32 = 1 // Points for a troll for each effect used on the painter
+	POINTS_STICKER_VOTE float32 = 1 // Points for each vote on a sticker of the player
 )
 
 var (
@@ -36,24 +72,48 @@ var (
 	// Duration of the gallery
 	TIME_GAME_GALLERY_S = 60
 
-	/// Time how long a "troll" effect does last in milliseconds
-	TIME_GAME_TROLL_EFFECT_DURATION_MS = 6000
-
 	/// Timeout for generic announcements
 	TIME_ANNOUNCE_GENERIC time.Duration = 3 * time.Second
 
 	/// Duration of a regular popup
 	TIME_POPUP_DURATION_MS = 1500
+
+	// A player may send another chat message after this time
+	TIME_CHAT_MESSAGE_INTERVAL time.Duration = 2 * time.Second
 )
 
 const (
 	// Error messages:
 	TEXT_ERROR_NICK_EMPTY     string = "Empty nick not allowed!"
 	TEXT_ERROR_NICK_TOO_LONG  string = "Nickname too long!"
+	TEXT_ERROR_NICK_INVALID   string = "Nickname contains invalid characters!"
 	TEXT_ERROR_SESSION_EMPTY  string = "Empty session id not allowed!"
 	TEXT_ERROR_BAD_SESSION    string = "Session does not exist!"
 	TEXT_ERROR_SESSION_ONLINE string = "Session is already running!"
 	TEXT_ERROR_SESSION_FULL   string = "Lobby is already full!"
+	TEXT_ERROR_BAD_SECRET     string = "Invalid join secret!"
+	TEXT_ERROR_WRONG_PASSWORD string = "Wrong password!"
+	TEXT_ERROR_BAD_INVITE     string = "Invalid invite link!"
+	TEXT_ERROR_PASS_TOO_LONG  string = "Password too long!"
+
+	TEXT_ERROR_BAD_MAX_PLAYERS    string = "Invalid number of players!"
+	TEXT_ERROR_TOO_MANY_PLAYERS   string = "There are already more players in the lobby!"
+	TEXT_ERROR_BAD_PAINTING_TIME  string = "Invalid painting time!"
+	TEXT_ERROR_BAD_GAME_MODE      string = "Unknown game mode!"
+	TEXT_ERROR_BAD_PROMPT_PACKS   string = "Select at least one known prompt pack!"
+	TEXT_ERROR_BAD_STICKER_PACKS  string = "Select at least one known sticker pack!"
+	TEXT_ERROR_BAD_BACKDROP_PACKS string = "Select at least one known backdrop pack!"
+	TEXT_ERROR_BAD_EFFECTS        string = "Select at least one known effect!"
+	TEXT_ERROR_BAD_TURN_ORDER     string = "Unknown turn order!"
+	TEXT_ERROR_BAD_PAINT_TURNS    string = "Invalid number of turns!"
+	TEXT_ERROR_BAD_ROUNDS         string = "Invalid number of rounds!"
+	TEXT_ERROR_BAD_STICKERS       string = "Invalid number of stickers!"
+	TEXT_ERROR_PROMPT_TOO_SHORT   string = "Prompt too short!"
+	TEXT_ERROR_PROMPT_TOO_LONG    string = "Prompt too long!"
+	TEXT_ERROR_PROMPT_BLOCKED     string = "Mind your language!"
+	TEXT_ERROR_TOO_MANY_PROMPTS   string = "You already submitted enough prompts!"
+	TEXT_ERROR_DUPLICATE_PROMPT   string = "Someone already submitted this prompt!"
+	TEXT_ERROR_PACK_PROMPT        string = "This prompt is already part of the prompt packs!"
 
 	// Popup messages:
 	TEXT_POPUP_START_PAINTING   string = "Start painting the prompt!"
@@ -63,6 +123,14 @@ const (
 	TEXT_POPUP_START_STICKERING string = "Let's make a mess!"
 	TEXT_POPUP_STOP_STICKERING  string = "The results are in!"
 	TEXT_POPUP_TIMES_UP         string = "Someone's sleepy!"
+	TEXT_POPUP_RENAMED          string = "Your name was taken, you're now "
+	TEXT_POPUP_CHAT_TOO_FAST    string = "Slow down, chatterbox!"
+	TEXT_POPUP_CHAT_SPOILER     string = "Nice try, no spoilers!"
+	TEXT_POPUP_GUESS_CORRECT    string = "You got it! It's: "
+	TEXT_POPUP_GUESS_CLOSE      string = "So close!"
+	TEXT_POPUP_GUESSED_BY       string = " guessed the prompt!"
+	TEXT_POPUP_TURN_SKIPPED     string = " skipped their turn!"
+	TEXT_POPUP_NO_STICKER       string = "Let the others make a mess this time!"
 
 	// Vote Prompts:
 	TEXT_VOTE_PROMPT     string = "Select a prompt"
@@ -79,6 +147,8 @@ const (
 	TEXT_ANNOUNCE_YOU_ARE_PAINTER Announcement = "You are the painter. Brace yourself!"
 	TEXT_ANNOUNCE_VOTE_NOW        Announcement = "Rate the picture $painter has drawn."
 	TEXT_ANNOUNCE_WINNER          Announcement = "And the winner is ..."
+	TEXT_ANNOUNCE_GUESS_TROLL     Announcement = "Guess what $painter is drawing!"
+	TEXT_ANNOUNCE_GUESS_PAINTER   Announcement = "Pick a prompt, but keep it secret!"
 )
 
 type AnnouncementContext struct {
@@ -88,7 +158,3 @@ type AnnouncementContext struct {
 func (anno Announcement) Format(ctx AnnouncementContext) string {
 	return strings.ReplaceAll(string(anno), "$painter", ctx.PainterName)
 }
-
-//go:embed drawing_prompts_the_other_kind_of_drawcalls.txt
-var fileData []byte
-var AVAILABLE_PROMPTS = strings.Split(string(fileData), "\n")
@@ -0,0 +1,182 @@
+package game
+
+import (
+	mrand "math/rand"
+	"time"
+)
+
+// What happens when an effect is triggered while it's still active.
+type EffectStacking int
+
+const (
+	EFFECT_STACKING_RESTART EffectStacking = 0 // the effect starts over
+	EFFECT_STACKING_EXTEND  EffectStacking = 1 // the duration is added to the remaining time
+	EFFECT_STACKING_BLOCK   EffectStacking = 2 // the effect isn't offered while it's active
+)
+
+type TrollEffect struct {
+	Id         Effect
+	Duration   time.Duration
+	Cooldown   time.Duration      // the effect isn't offered again until this time after it ended
+	Parameters map[string]float32 // intensity of the effect, interpreted by the frontend
+	Stacking   EffectStacking
+}
+
+// All effects the trolls can choose from.
+var TROLL_EFFECTS = map[Effect]*TrollEffect{
+	EFFECT_FLASHLIGHT: {
+		Id:       EFFECT_FLASHLIGHT,
+		Duration: 6 * time.Second,
+		Cooldown: 10 * time.Second,
+		Parameters: map[string]float32{
+			"radius": 50, // pixels around the pencil that stay visible
+		},
+		Stacking: EFFECT_STACKING_RESTART,
+	},
+	EFFECT_DRUNK: {
+		Id:       EFFECT_DRUNK,
+		Duration: 6 * time.Second,
+		Cooldown: 10 * time.Second,
+		Parameters: map[string]float32{
+			"strength": 1,
+		},
+		Stacking: EFFECT_STACKING_EXTEND,
+	},
+	EFFECT_FLIP: {
+		Id:       EFFECT_FLIP,
+		Duration: 6 * time.Second,
+		Cooldown: 15 * time.Second,
+		Stacking: EFFECT_STACKING_BLOCK, // flipping twice would undo the flip
+	},
+	EFFECT_SWAP_TOOL: {
+		Id:       EFFECT_SWAP_TOOL,
+		Duration: 6 * time.Second,
+		Cooldown: 10 * time.Second,
+		Stacking: EFFECT_STACKING_BLOCK, // swapping twice would undo the swap
+	},
+	EFFECT_LOCK_PENCIL: {
+		Id:       EFFECT_LOCK_PENCIL,
+		Duration: 4 * time.Second,
+		Cooldown: 20 * time.Second,
+		Stacking: EFFECT_STACKING_RESTART,
+	},
+}
+
+// Selects up to `count` random effects for the next troll. Only enabled
+// effects are offered. Active effects are offered again if they stack,
+// effects that ended are offered again once they cooled down.
+func (session *Session) selectEffects(rng *mrand.Rand, count int) []string {
+	now := time.Now()
+
+	available := make([]string, 0, len(session.Settings.Effects))
+	for _, id := range session.Settings.Effects {
+		effect := TROLL_EFFECTS[id]
+		end, used := session.effectEnds[id]
+		if session.activeEffects[id] {
+			if effect.Stacking == EFFECT_STACKING_BLOCK {
+				continue
+			}
+		} else if used && now.Before(end.Add(effect.Cooldown)) {
+			continue
+		}
+		available = append(available, string(id))
+	}
+
+	if len(available) < count {
+		count = len(available)
+	}
+	return nElementsFrom(rng, available, count)
+}
+
+// Returns how many trolls may use an effect in the same time slice, so
+// every troll gets a turn during the painting if possible.
+func trollsPerSlice(trolls int, painting_time int) int {
+	slices := painting_time / TIME_GAME_NEXT_TROLLEFFECT_S
+	if slices < 1 {
+		slices = 1
+	}
+
+	count := (trolls + slices - 1) / slices
+	if count > LIMIT_TROLLS_PER_SLICE {
+		count = LIMIT_TROLLS_PER_SLICE
+	}
+	if count > trolls {
+		count = trolls
+	}
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+// Starts the effect for the painter according to its stacking rules. Other
+// active effects keep running, so trolls can combine them. Returns false if
+// the effect can't be stacked.
+func (session *Session) triggerEffect(effect *TrollEffect) bool {
+	now := time.Now()
+
+	end := session.effectEnds[effect.Id]
+	active := session.activeEffects[effect.Id]
+
+	switch {
+	case active && effect.Stacking == EFFECT_STACKING_BLOCK:
+		session.ServerPrint("Effect ", effect.Id, " is already active")
+		return false
+	case active && effect.Stacking == EFFECT_STACKING_EXTEND:
+		end = end.Add(effect.Duration)
+	default:
+		end = now.Add(effect.Duration)
+	}
+	session.effectEnds[effect.Id] = end
+	session.activeEffects[effect.Id] = true
+
+	session.broadcastActiveEffects()
+	return true
+}
+
+// Ends all effects whose time is up. Called on every tick of the session
+// timer, so the clients don't have to keep track of the durations.
+func (session *Session) expireEffects() {
+	now := time.Now()
+
+	changed := false
+	for id := range session.activeEffects {
+		if !now.Before(session.effectEnds[id]) {
+			delete(session.activeEffects, id)
+			changed = true
+		}
+	}
+
+	if changed {
+		session.broadcastActiveEffects()
+	}
+}
+
+// Ends all effects at once, e.g. when the painting time is over.
+func (session *Session) clearEffects() {
+	session.activeEffects = make(map[Effect]bool)
+	session.broadcastActiveEffects()
+}
+
+// Tells everyone which effects are active right now.
+func (session *Session) broadcastActiveEffects() {
+	effects := make([]ActiveEffect, 0, len(session.activeEffects))
+	for _, id := range ALL_EFFECT_ITEMS { // keeps the order stable
+		if !session.activeEffects[id] {
+			continue
+		}
+
+		parameters := TROLL_EFFECTS[id].Parameters
+		if parameters == nil {
+			parameters = map[string]float32{}
+		}
+		effects = append(effects, ActiveEffect{
+			Effect:     id,
+			Parameters: parameters,
+		})
+	}
+
+	session.Broadcast(&ActiveEffectsChangedEvent{
+		Effects: effects,
+	})
+}
@@ -0,0 +1,46 @@
+package game
+
+import (
+	mrand "math/rand"
+	"testing"
+	"time"
+)
+
+func TestSelectEffects(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		effect  Effect
+		active  bool
+		end     time.Time // zero if the effect wasn't used yet
+		offered bool
+	}{
+		{"unused", EFFECT_DRUNK, false, time.Time{}, true},
+		{"active restart", EFFECT_FLASHLIGHT, true, now.Add(3 * time.Second), true},
+		{"active extend", EFFECT_DRUNK, true, now.Add(3 * time.Second), true},
+		{"active block", EFFECT_FLIP, true, now.Add(3 * time.Second), false},
+		{"cooling down", EFFECT_LOCK_PENCIL, false, now.Add(-time.Second), false},
+		{"cooled down", EFFECT_SWAP_TOOL, false, now.Add(-time.Minute), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := &Session{
+				Settings:      defaultSessionSettings(),
+				effectEnds:    make(map[Effect]time.Time),
+				activeEffects: make(map[Effect]bool),
+			}
+			session.Settings.Effects = []Effect{test.effect}
+			if !test.end.IsZero() {
+				session.effectEnds[test.effect] = test.end
+			}
+			session.activeEffects[test.effect] = test.active
+
+			options := session.selectEffects(mrand.New(mrand.NewSource(1)), LIMIT_EFFECT_OPTIONS)
+			if offered := len(options) == 1; offered != test.offered {
+				t.Errorf("%s offered: %v, want %v", test.effect, offered, test.offered)
+			}
+		})
+	}
+}
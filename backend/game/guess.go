@@ -0,0 +1,185 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// Share of the prompt's keywords a guess must contain to be correct.
+	guessCorrectRatio = 0.6
+
+	// Share of the keywords that tells the troll they are close.
+	guessCloseRatio = 0.3
+)
+
+// Words that don't tell anything about the painting.
+var guessStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "of": true, "in": true, "on": true,
+	"at": true, "to": true, "for": true, "with": true, "and": true, "or": true,
+	"is": true, "are": true, "its": true, "his": true, "her": true,
+	"their": true, "your": true, "you": true, "have": true, "has": true,
+	"ever": true, "some": true, "that": true, "this": true, "from": true,
+}
+
+// State of the prompt guessing in the current round.
+type guessRound struct {
+	prompt   string
+	keywords []string
+	solved   map[*Player]bool // trolls that guessed the prompt
+}
+
+func createGuessRound(prompt string) *guessRound {
+	return &guessRound{
+		prompt:   prompt,
+		keywords: guessKeywords(prompt),
+		solved:   make(map[*Player]bool),
+	}
+}
+
+// Splits the text into words that are compared by their skeleton, so
+// spelling and case don't matter.
+func guessKeywords(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	keywords := make([]string, 0, len(words))
+	for _, word := range words {
+		if guessStopWords[strings.ToLower(word)] {
+			continue
+		}
+		keywords = append(keywords, nickNameSkeleton(word))
+	}
+	return keywords
+}
+
+func levenshteinDistance(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// Two words match if they only differ by a typo. Longer words may have
+// more typos.
+func keywordsMatch(expected string, actual string) bool {
+	allowed := 0
+	if len(expected) >= 5 {
+		allowed = 1
+	}
+	if len(expected) >= 8 {
+		allowed = 2
+	}
+	return levenshteinDistance([]rune(expected), []rune(actual)) <= allowed
+}
+
+// Returns the share of the prompt's keywords that are found in the guess.
+func (round *guessRound) rate(guess string) float64 {
+	guessed := guessKeywords(guess)
+
+	if len(round.keywords) == 0 {
+		// The prompt only consists of stop words, so it must be guessed exactly.
+		if nickNameSkeleton(guess) == nickNameSkeleton(round.prompt) {
+			return 1
+		}
+		return 0
+	}
+
+	matches := 0
+	for _, expected := range round.keywords {
+		for _, actual := range guessed {
+			if keywordsMatch(expected, actual) {
+				matches += 1
+				break
+			}
+		}
+	}
+	return float64(matches) / float64(len(round.keywords))
+}
+
+// Faster guesses get more points, but every correct guess gets at least one.
+func guessPoints(seconds_left int, total_seconds int) int {
+	if total_seconds <= 0 || seconds_left < 0 {
+		return 1
+	}
+	return 1 + (POINTS_GUESS_MAX-1)*seconds_left/total_seconds
+}
+
+// Rates the guess of a troll and credits points to the troll and the
+// painters if it was correct.
+func (session *Session) handleGuess(round *guessRound, painters []*Player, pmsg PlayerMessage, msg *GuessCommand, timer *autoGameTimer) {
+	guesser := pmsg.Player
+
+	if containsPlayer(painters, guesser) {
+		session.ServerPrint("painter tried to guess. BAD BOY!")
+		return
+	}
+	if round.solved[guesser] {
+		return
+	}
+
+	guess := cleanChatMessage(msg.Guess)
+	if guess == "" || !session.checkChatBudget(guesser) {
+		return
+	}
+
+	rating := round.rate(guess)
+
+	switch {
+	case rating >= guessCorrectRatio:
+		points := guessPoints(timer.timeLeft, session.Settings.PaintingTime)
+		session.ServerPrint("Player ", guesser.NickName, " guessed the prompt for ", points, " points")
+
+		round.solved[guesser] = true
+		delete(session.chatRules.uninformed, guesser) // they may not spoil it for the others now
+
+		session.scoreCard(guesser).guessing += float32(points)
+		for _, painter := range painters {
+			session.scoreCard(painter).guessing += float32(POINTS_GUESS_PAINTER)
+		}
+		session.BroadcastPlayers(nil, nil)
+
+		guesser.Send(&PopUpEvent{
+			Message:  TEXT_POPUP_GUESS_CORRECT + round.prompt,
+			Duration: TIME_POPUP_DURATION_MS,
+		})
+		session.BroadcastExcept(&PopUpEvent{
+			Message:  guesser.NickName + TEXT_POPUP_GUESSED_BY,
+			Duration: TIME_POPUP_DURATION_MS,
+		}, guesser)
+
+	case rating >= guessCloseRatio:
+		guesser.Send(&PopUpEvent{
+			Message:  TEXT_POPUP_GUESS_CLOSE,
+			Duration: TIME_POPUP_DURATION_MS,
+		})
+
+	default:
+		// Wrong guesses are part of the fun, so everyone sees them:
+		session.Broadcast(&ChatMessageEvent{
+			Sender:  session.playerInfo(guesser),
+			Message: chatFilter(guess),
+		})
+	}
+}
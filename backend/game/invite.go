@@ -0,0 +1,75 @@
+package game
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Contents of an invite link. Kept short as it ends up in URLs.
+type invite struct {
+	SessionId  string `json:"s"`
+	JoinSecret string `json:"k,omitempty"`
+	Password   string `json:"p,omitempty"`
+}
+
+func (session *Session) createInviteCode() string {
+	encoded, err := json.Marshal(invite{
+		SessionId:  session.Id,
+		JoinSecret: session.joinSecret,
+		Password:   session.password,
+	})
+	if err != nil {
+		panic(err) // can't happen for plain strings
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// Decodes the code of an invite link. Returns false if the code is malformed.
+func parseInviteCode(code string) (invite, bool) {
+	var result invite
+
+	decoded, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil {
+		return result, false
+	}
+	if json.Unmarshal(decoded, &result) != nil || result.SessionId == "" {
+		return result, false
+	}
+
+	return result, true
+}
+
+func (session *Session) checkPassword(password string) bool {
+	if session.password == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(password), []byte(session.password)) == 1
+}
+
+// Returns a fresh invite to the host. The password is only changed if the
+// host asks for it, so creating an invite never removes it by accident.
+func (session *Session) handleCreateInvite(pmsg PlayerMessage, msg *CreateInviteCommand) {
+	if pmsg.Player != session.HostPlayer {
+		session.ServerPrint("Player ", pmsg.Player.NickName, " tried to create an invite. BAD BOY!")
+		return
+	}
+
+	if msg.SetPassword && len(msg.Password) > LIMIT_MAX_PASSWORD_LEN {
+		pmsg.Player.Send(&PopUpEvent{
+			Message:  TEXT_ERROR_PASS_TOO_LONG,
+			Duration: TIME_POPUP_DURATION_MS,
+		})
+		return
+	}
+
+	if msg.SetPassword && msg.Password != session.password {
+		session.ServerPrint("Password changed")
+		session.password = msg.Password
+		session.publishSnapshot()
+	}
+
+	pmsg.Player.Send(&InviteCreatedEvent{
+		Invite: session.createInviteCode(),
+	})
+}
@@ -0,0 +1,93 @@
+package game
+
+import "sort"
+
+// State of a session as seen by the lobby browser. Written by the session
+// loop and read by players looking for a session, guarded by sessionsMu.
+type sessionSnapshot struct {
+	listed bool // the session is public and accepts new players
+	info   PublicSessionInfo
+}
+
+// Updates what the lobby browser shows about this session. Must be called
+// by the session loop after the players, flags or settings changed.
+func (session *Session) publishSnapshot() {
+	host_name := ""
+	if session.HostPlayer != nil {
+		host_name = session.HostPlayer.NickName
+	}
+
+	snapshot := sessionSnapshot{
+		listed: session.Flags.Public &&
+			session.Flags.Joinable &&
+			session.joinSecret == "" &&
+			session.password == "" &&
+			len(session.Players) < session.Settings.MaxPlayers,
+		info: PublicSessionInfo{
+			SessionId: session.Id,
+			HostName:  host_name,
+			Players:   len(session.Players),
+			Settings:  session.Settings,
+		},
+	}
+
+	sessionsMu.Lock()
+	session.snapshot = snapshot
+	sessionsMu.Unlock()
+}
+
+// Returns all public sessions that can be joined right now, the fullest
+// ones first.
+func listedSessions() []*Session {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	listed := make([]*Session, 0)
+	for id, session := range sessions {
+		// skip aliases like the debug session
+		if id == session.Id && session.snapshot.listed {
+			listed = append(listed, session)
+		}
+	}
+
+	sort.Slice(listed, func(i, j int) bool {
+		a, b := listed[i].snapshot.info, listed[j].snapshot.info
+		if a.Players != b.Players {
+			return a.Players > b.Players
+		}
+		return a.SessionId < b.SessionId
+	})
+
+	return listed
+}
+
+func ListPublicSessions() []PublicSessionInfo {
+	listed := listedSessions()
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	infos := make([]PublicSessionInfo, len(listed))
+	for i, session := range listed {
+		infos[i] = session.snapshot.info
+	}
+	return infos
+}
+
+// Places the player in the fullest public lobby that has room left or
+// creates a new public session if there is none.
+func QuickMatch(player *Player) {
+	for _, session := range listedSessions() {
+		result := make(chan string, 1)
+
+		// The lobby might have filled up in the meantime, so try the next
+		// one if joining fails.
+		if session.RequestJoin(JoinRequest{Player: player, Result: result}) && <-result == "" {
+			return
+		}
+	}
+
+	CreateSession(player, SessionOptions{
+		Public: true,
+	})
+}
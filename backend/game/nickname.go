@@ -0,0 +1,145 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Cleans up a user provided nickname. Returns the normalized nickname
+// or an error text if the nickname isn't acceptable.
+func normalizeNickName(raw string) (string, string) {
+
+	nick := norm.NFC.String(raw)
+
+	for _, r := range nick {
+		// Rejects control characters as well as invisible formatting
+		// characters like bidi overrides. Joiners and tags are required
+		// for emoji sequences, though.
+		if r == zeroWidthJoiner || isEmojiTag(r) {
+			continue
+		}
+		if unicode.In(r, unicode.Cc, unicode.Cf, unicode.Co, unicode.Cs) || r == unicode.ReplacementChar {
+			return "", TEXT_ERROR_NICK_INVALID
+		}
+	}
+
+	// Collapse all kinds of whitespace into a single regular space:
+	nick = strings.Join(strings.Fields(nick), " ")
+
+	if nick == "" {
+		return "", TEXT_ERROR_NICK_EMPTY
+	}
+	if len(splitGraphemes(nick)) > LIMIT_MAX_NICKNAME_LEN {
+		return "", TEXT_ERROR_NICK_TOO_LONG
+	}
+
+	return nick, ""
+}
+
+// Maps characters that look alike to a common representative, so
+// "Pau1" and "PAUL" or a cyrillic "Раul" can't pose as "Paul".
+var nickNameConfusables = map[rune]rune{
+	'0': 'o', '1': 'l', 'i': 'l', '|': 'l', '!': 'l', '3': 'e', '4': 'a',
+	'5': 's', '7': 't', '8': 'b', '@': 'a', '$': 's',
+
+	// cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h',
+	'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'і': 'l',
+	'ј': 'j', 'ѕ': 's', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w',
+
+	// greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'l', 'κ': 'k', 'ν': 'v',
+	'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x',
+}
+
+// Computes a representation of the nickname that is equal for all names
+// that are hard to tell apart for humans.
+func nickNameSkeleton(nick string) string {
+	folded := strings.ToLower(norm.NFKD.String(nick))
+
+	var sb strings.Builder
+	for _, r := range folded {
+		if unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '!' && r != '@') || unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Cf) {
+			continue
+		}
+		if mapped, ok := nickNameConfusables[r]; ok {
+			r = mapped
+		}
+		sb.WriteRune(r)
+	}
+
+	skeleton := sb.String()
+	skeleton = strings.ReplaceAll(skeleton, "rn", "m")
+	skeleton = strings.ReplaceAll(skeleton, "vv", "w")
+	return skeleton
+}
+
+// Shortens the nickname to at most `count` user perceived characters.
+func truncateNickName(nick string, count int) string {
+	clusters := splitGraphemes(nick)
+	if len(clusters) > count {
+		clusters = clusters[:count]
+	}
+	return strings.TrimSpace(strings.Join(clusters, ""))
+}
+
+const zeroWidthJoiner = '\u200D'
+
+func isEmojiTag(r rune) bool {
+	return r >= 0xE0020 && r <= 0xE007F
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// Runes that never start a new user perceived character.
+func isGraphemeExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		(r >= 0x1F3FB && r <= 0x1F3FF) || // emoji skin tones
+		isEmojiTag(r)
+}
+
+// Splits the text into user perceived characters. This is a simplified
+// version of the unicode grapheme cluster rules that handles combining
+// marks, emoji sequences and flags.
+func splitGraphemes(text string) []string {
+	runes := []rune(text)
+	clusters := make([]string, 0, len(runes))
+
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || isGraphemeBoundary(runes, start, i) {
+			clusters = append(clusters, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	return clusters
+}
+
+// Checks if a new grapheme starts at runes[i]. runes[start] is the start of
+// the current grapheme.
+func isGraphemeBoundary(runes []rune, start int, i int) bool {
+	prev, r := runes[i-1], runes[i]
+
+	switch {
+	case prev == '\r' && r == '\n':
+		return false
+
+	case prev == zeroWidthJoiner || r == zeroWidthJoiner || isGraphemeExtend(r):
+		return false
+
+	case isRegionalIndicator(prev) && isRegionalIndicator(r):
+		// Flags are pairs of regional indicators:
+		count := 0
+		for j := i - 1; j >= start && isRegionalIndicator(runes[j]); j-- {
+			count += 1
+		}
+		return count%2 == 0
+	}
+
+	return true
+}
@@ -0,0 +1,96 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitGraphemes(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"ascii", "Paul", []string{"P", "a", "u", "l"}},
+		{"combining mark", "e\u0301a", []string{"e\u0301", "a"}},
+		{"several combining marks", "a\u0323\u0308b", []string{"a\u0323\u0308", "b"}},
+		{"skin tone", "\U0001F44D\U0001F3FD!", []string{"\U0001F44D\U0001F3FD", "!"}},
+		{"zwj family", "\U0001F468\u200D\U0001F469\u200D\U0001F467", []string{"\U0001F468\u200D\U0001F469\u200D\U0001F467"}},
+		{"zwj with skin tone", "\U0001F469\U0001F3FE\u200D\U0001F4BB x", []string{"\U0001F469\U0001F3FE\u200D\U0001F4BB", " ", "x"}},
+		{"flags", "\U0001F1E9\U0001F1EA\U0001F1EB\U0001F1F7", []string{"\U0001F1E9\U0001F1EA", "\U0001F1EB\U0001F1F7"}},
+		{"odd regional indicator", "\U0001F1E9\U0001F1EA\U0001F1EB", []string{"\U0001F1E9\U0001F1EA", "\U0001F1EB"}},
+		{"tag sequence", "\U0001F3F4\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F", []string{"\U0001F3F4\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F"}},
+		{"empty", "", []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := splitGraphemes(test.text)
+			if strings.Join(got, "|") != strings.Join(test.want, "|") || len(got) != len(test.want) {
+				t.Errorf("splitGraphemes(%q) = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeNickName(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		want   string
+		reason string
+	}{
+		{"plain", "Paul", "Paul", ""},
+		{"whitespace collapsed", "  Paul   the\u00A0Great ", "Paul the Great", ""},
+		{"composed", "Jose\u0301", "Jos\u00E9", ""},
+		{"empty", "   ", "", TEXT_ERROR_NICK_EMPTY},
+		{"control character", "Pa\x07ul", "", TEXT_ERROR_NICK_INVALID},
+		{"tab", "Paul\tthe Great", "", TEXT_ERROR_NICK_INVALID},
+		{"bidi override", "Pa\u202Eul", "", TEXT_ERROR_NICK_INVALID},
+		{"zwj emoji allowed", "Fam \U0001F468\u200D\U0001F469\u200D\U0001F467", "Fam \U0001F468\u200D\U0001F469\u200D\U0001F467", ""},
+		{"at the limit", strings.Repeat("a", LIMIT_MAX_NICKNAME_LEN), strings.Repeat("a", LIMIT_MAX_NICKNAME_LEN), ""},
+		{"above the limit", strings.Repeat("a", LIMIT_MAX_NICKNAME_LEN+1), "", TEXT_ERROR_NICK_TOO_LONG},
+		{"combining marks at the limit", strings.Repeat("a\u0323\u0308", LIMIT_MAX_NICKNAME_LEN), strings.Repeat("\u1EA1\u0308", LIMIT_MAX_NICKNAME_LEN), ""},
+		{"zwj emoji at the limit", strings.Repeat("\U0001F468\u200D\U0001F469\u200D\U0001F467", LIMIT_MAX_NICKNAME_LEN), strings.Repeat("\U0001F468\u200D\U0001F469\u200D\U0001F467", LIMIT_MAX_NICKNAME_LEN), ""},
+		{"zwj emoji above the limit", strings.Repeat("\U0001F468\u200D\U0001F469", LIMIT_MAX_NICKNAME_LEN+1), "", TEXT_ERROR_NICK_TOO_LONG},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, reason := normalizeNickName(test.raw)
+			if got != test.want || reason != test.reason {
+				t.Errorf("normalizeNickName(%q) = (%q, %q), want (%q, %q)", test.raw, got, reason, test.want, test.reason)
+			}
+		})
+	}
+}
+
+func TestNickNameSkeleton(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		collide bool
+	}{
+		{"Paul", "paul", true},
+		{"Paul", "PAUL", true},
+		{"Paul", "Pau1", true},
+		{"Paul", "P a u l", true},
+		{"Paul", "P.a-u_l", true},
+		{"Paul", "\u0420\u0430ul", true}, // cyrillic Er and a
+		{"Paul", "P\u03B1ul", true},      // greek alpha
+		{"Paul", "Pau\u0308l", true},     // u with diaeresis
+		{"Paul", "Pa\u200Bul", true},     // zero width space
+		{"Tom", "T0rn", true},
+		{"Owen", "Ovven", true},
+		{"Paul", "Pauline", false},
+		{"Paul", "Saul", false},
+		{"Anna", "Anne", false},
+	}
+
+	for _, test := range tests {
+		collide := nickNameSkeleton(test.a) == nickNameSkeleton(test.b)
+		if collide != test.collide {
+			t.Errorf("skeletons of %q (%q) and %q (%q) collide: %v, want %v",
+				test.a, nickNameSkeleton(test.a), test.b, nickNameSkeleton(test.b), collide, test.collide)
+		}
+	}
+}
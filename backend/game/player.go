@@ -1,212 +1,292 @@
 package game
 
 import (
+	"fmt"
 	"log"
 	"reflect"
+	"strconv"
 	"sync"
-	"time"
-
-	"github.com/gorilla/websocket"
+	"sync/atomic"
 )
 
 const (
-	// Time allowed to write a message to the peer.
-	writeWait = 10 * time.Second
+	// Maximum number of messages queued for a peer before it's considered
+	// stalled and gets dropped.
+	maxPendingMessages = 256
+)
 
-	// Time allowed to read the next pong message from the peer.
-	pongWait = 60 * time.Second
+// Connects a player to their client. The game doesn't care how messages
+// travel, the transport takes the outbound messages of the player and
+// passes the received ones to Player.HandleInbound.
+type Transport interface {
+	// Terminates the connection to the client. Must be safe to call
+	// multiple times and from any goroutine.
+	Close()
+}
 
-	// Send pings to peer with this period. Must be less than pongWait.
-	pingPeriod = (pongWait * 9) / 10
+// Messages of these types only carry the latest state, so a newer message
+// replaces a still queued older one instead of piling up for slow peers.
+var coalescedMessageTags = map[string]bool{
+	PAINTING_CHANGED_EVENT_TAG: true,
+	TIMER_CHANGED_EVENT_TAG:    true,
+}
 
-	// Maximum message size allowed from peer.
-	maxMessageSize = 512
-)
+type outboundMessage struct {
+	tag  string
+	data []byte
+}
 
-// client.hub.register <- client
+var lastPlayerId uint64
 
 type Player struct {
-	mu     sync.Mutex
-	closed bool
+	mu      sync.Mutex
+	closed  bool
+	dropped bool // the peer didn't keep up with the outbound messages
+
+	// Serializes the inbound messages, as some transports receive them
+	// concurrently.
+	inboundMu sync.Mutex
 
-	ws *websocket.Conn
+	transport Transport
+
+	// Unique id of the player, other than the nickname it never changes.
+	Id string
 
 	Session *Session
 
 	NickName string
 
 	// NOTE(fqu):
-	// Must be a buffered channel, as we have to be able to send
-	// non-blockingly.
-	sendChan chan []byte
+	// Send must never block the session loop, so outbound messages are
+	// queued here (guarded by mu) and the transport is woken up via sendSignal.
+	sendQueue  []outboundMessage
+	sendSignal chan struct{}
 }
 
-func CreatePlayer(ws *websocket.Conn) *Player {
+// Creates a new player that talks to its client via `transport`. The
+// transport must start delivering messages after this.
+func CreatePlayer(transport Transport) *Player {
 	player := &Player{
-		ws:       ws,
-		Session:  nil,
-		NickName: "Anonymouse",
+		transport: transport,
+		Id:        strconv.FormatUint(atomic.AddUint64(&lastPlayerId, 1), 10),
+		Session:   nil,
+		NickName:  "Anonymouse",
 
-		sendChan: make(chan []byte, 256),
+		sendSignal: make(chan struct{}, 1),
 	}
 
 	player.Send(&ChangeGameViewEvent{
 		View: GAME_VIEW_TITLE,
 	})
 
-	go player.writePump()
-	go player.readPump()
-
 	return player
 }
 
+// Queues a message for the player. Never blocks, so a stalled client can't
+// freeze the session loop. Clients that fall too far behind are dropped.
 func (player *Player) Send(msg Message) {
-	if player.closed {
-		return
-	}
 	encoded_msg, err := SerializeMessage(msg)
 	if err != nil {
 		log.Fatalln("failed to serialize message for client: ", err, msg)
 	}
-	player.sendChan <- encoded_msg
+	tag := msg.GetJsonType()
+
+	player.mu.Lock()
+	defer player.mu.Unlock()
+
+	if player.closed || player.dropped {
+		return
+	}
+
+	if coalescedMessageTags[tag] {
+		for i, pending := range player.sendQueue {
+			if pending.tag == tag {
+				player.sendQueue = append(player.sendQueue[:i], player.sendQueue[i+1:]...)
+				break
+			}
+		}
+	}
+
+	if len(player.sendQueue) >= maxPendingMessages {
+		log.Println("client ", player.NickName, " is too slow, dropping it")
+		player.dropped = true
+		player.sendQueue = nil
+	} else {
+		player.sendQueue = append(player.sendQueue, outboundMessage{
+			tag:  tag,
+			data: encoded_msg,
+		})
+	}
+
+	player.wakeWriter()
 }
 
-func (player *Player) Close() {
+// Signalled when new outbound messages can be fetched with TakeOutbound.
+func (player *Player) OutboundSignal() <-chan struct{} {
+	return player.sendSignal
+}
 
+func (player *Player) wakeWriter() {
+	select {
+	case player.sendSignal <- struct{}{}:
+	default:
+		// writer is already signalled
+	}
+}
+
+// Takes all queued messages in their encoded form. Returns false if the
+// player shouldn't receive any messages anymore and the transport should
+// shut down.
+func (player *Player) TakeOutbound() ([][]byte, bool) {
 	player.mu.Lock()
 	defer player.mu.Unlock()
 
+	messages := make([][]byte, len(player.sendQueue))
+	for i, message := range player.sendQueue {
+		messages[i] = message.data
+	}
+	player.sendQueue = nil
+
+	return messages, !player.closed && !player.dropped
+}
+
+func (player *Player) Close() {
+
+	player.mu.Lock()
 	if player.closed {
+		player.mu.Unlock()
 		return
 	}
+	player.closed = true
+	player.sendQueue = nil
 
-	if player.Session != nil {
-		player.Session.LeaveChan <- player
-		player.Session = nil
-	}
-	player.ws.Close()
-	close(player.sendChan)
+	session := player.Session
+	player.Session = nil
+	player.mu.Unlock()
 
-	player.closed = true
+	player.wakeWriter()
+	player.transport.Close()
+
+	// NOTE: Must happen without holding the lock, as the session loop
+	// might be waiting in Send() for it.
+	if session != nil {
+		session.RequestLeave(player)
+	}
 }
 
-// Pumps messages from websocket to the session or creates/joins a new session.
-func (player *Player) readPump() {
-	defer player.Close()
-	// player.ws.SetReadLimit(maxMessageSize)
-	player.ws.SetReadDeadline(time.Now().Add(pongWait))
-	player.ws.SetPongHandler(func(string) error {
-		player.ws.SetReadDeadline(time.Now().Add(pongWait))
-		return nil
-	})
-	for {
-		_, raw_message, err := player.ws.ReadMessage()
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("websocket error: %v", err)
-			}
-			break
-		}
+// Passes a message received from the client to the session or
+// creates/joins a new session. Returns an error if the client misbehaved
+// and should be dropped.
+func (player *Player) HandleInbound(raw_message []byte) error {
+	player.inboundMu.Lock()
+	defer player.inboundMu.Unlock()
 
-		// log.Println("raw message from websocket", string(raw_message))
+	// log.Println("raw message from client", string(raw_message))
 
-		msg, err := DeserializeMessage(raw_message)
+	msg, err := DeserializeMessage(raw_message)
 
-		// log.Println("message from websocket", string(raw_message), reflect.TypeOf(msg), msg, err)
+	// log.Println("message from client", string(raw_message), reflect.TypeOf(msg), msg, err)
 
-		if err != nil {
-			log.Println("failed to read message from client: ", err)
-			return
+	if err != nil {
+		return fmt.Errorf("failed to read message from client: %w", err)
+	}
+	// Close() may clear the session from another goroutine at any time:
+	player.mu.Lock()
+	session := player.Session
+	player.mu.Unlock()
+
+	if session != nil {
+		// log.Println("Forward message to session ", msg)
+		session.InboundDataChan <- PlayerMessage{
+			Player:  player,
+			Message: msg,
 		}
-		if player.Session != nil {
-			// log.Println("Forward message to session ", msg)
-			player.Session.InboundDataChan <- PlayerMessage{
-				Player:  player,
-				Message: msg,
+	} else {
+		switch v := msg.(type) {
+		case *CreateSessionCommand:
+			nick, reason := normalizeNickName(v.NickName)
+			if len(v.Password) > LIMIT_MAX_PASSWORD_LEN {
+				reason = TEXT_ERROR_PASS_TOO_LONG
 			}
-		} else {
-			switch v := msg.(type) {
-			case *CreateSessionCommand:
-				if v.NickName == "" {
-					player.Send(&JoinSessionFailedEvent{
-						Reason: TEXT_ERROR_NICK_EMPTY,
-					})
-				} else if len(v.NickName) > LIMIT_MAX_NICKNAME_LEN {
+			if reason != "" {
+				player.Send(&JoinSessionFailedEvent{
+					Reason: reason,
+				})
+			} else {
+				player.NickName = nick
+
+				session := CreateSession(player, SessionOptions{
+					Public:        v.Public,
+					RequireSecret: v.RequireSecret,
+					Password:      v.Password,
+				})
+
+				_ = session
+			}
+
+		case *JoinSessionCommand:
+			if v.Invite != "" {
+				invite, ok := parseInviteCode(v.Invite)
+				if !ok {
 					player.Send(&JoinSessionFailedEvent{
-						Reason: TEXT_ERROR_NICK_TOO_LONG,
+						Reason: TEXT_ERROR_BAD_INVITE,
 					})
-				} else {
-					player.NickName = v.NickName
-
-					session := CreateSession(player)
-
-					_ = session
+					break
 				}
+				v.SessionId = invite.SessionId
+				v.JoinSecret = invite.JoinSecret
+				v.Password = invite.Password
+			}
 
-			case *JoinSessionCommand:
-				if v.SessionId == "" {
-					player.Send(&JoinSessionFailedEvent{
-						Reason: TEXT_ERROR_SESSION_EMPTY,
-					})
-				} else if v.NickName == "" {
-					player.Send(&JoinSessionFailedEvent{
-						Reason: TEXT_ERROR_NICK_EMPTY,
-					})
-				} else if len(v.NickName) > LIMIT_MAX_NICKNAME_LEN {
+			nick, reason := normalizeNickName(v.NickName)
+			if v.SessionId == "" {
+				player.Send(&JoinSessionFailedEvent{
+					Reason: TEXT_ERROR_SESSION_EMPTY,
+				})
+			} else if reason != "" {
+				player.Send(&JoinSessionFailedEvent{
+					Reason: reason,
+				})
+			} else {
+				player.NickName = nick
+
+				session := FindSession(v.SessionId)
+
+				if session != nil && session.RequestJoin(JoinRequest{
+					Player:     player,
+					JoinSecret: v.JoinSecret,
+					Password:   v.Password,
+				}) {
+					// the session tells the player if joining worked
+				} else {
+					log.Println("didn't find session", v.SessionId)
 					player.Send(&JoinSessionFailedEvent{
-						Reason: TEXT_ERROR_NICK_TOO_LONG,
+						Reason: TEXT_ERROR_BAD_SESSION,
 					})
-				} else {
-					player.NickName = v.NickName
-
-					session := FindSession(v.SessionId)
-
-					if session != nil {
-						session.JoinChan <- player
-					} else {
-						log.Println("didn't find session", v.SessionId)
-						player.Send(&JoinSessionFailedEvent{
-							Reason: TEXT_ERROR_BAD_SESSION,
-						})
-					}
 				}
-
-			default:
-				log.Println("Bad command, dropping client, type was ", reflect.TypeOf(msg))
-				return
-			}
-		}
-	}
-}
-
-// Pumps messages from Player.SendChan to the websocket.
-func (player *Player) writePump() {
-	defer player.Close()
-
-	ticker := time.NewTicker(pingPeriod)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case message, ok := <-player.sendChan:
-			player.ws.SetWriteDeadline(time.Now().Add(writeWait))
-			if !ok {
-				// The hub closed the channel.
-				player.ws.WriteMessage(websocket.CloseMessage, []byte{})
-				return
 			}
 
-			err := player.ws.WriteMessage(websocket.TextMessage, message)
-			if err != nil {
-				log.Println("failed to send message to client: ", err)
-				return
+		case *ListPublicSessionsCommand:
+			player.Send(&PublicSessionsEvent{
+				Sessions: ListPublicSessions(),
+			})
+
+		case *QuickMatchCommand:
+			nick, reason := normalizeNickName(v.NickName)
+			if reason != "" {
+				player.Send(&JoinSessionFailedEvent{
+					Reason: reason,
+				})
+			} else {
+				player.NickName = nick
+
+				QuickMatch(player)
 			}
 
-		case <-ticker.C:
-			player.ws.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := player.ws.WriteMessage(websocket.PingMessage, nil); err != nil {
-				return
-			}
+		default:
+			return fmt.Errorf("bad command, type was %v", reflect.TypeOf(msg))
 		}
 	}
+
+	return nil
 }
@@ -0,0 +1,312 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	mrand "math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Id of the prompt pack that is built into the server.
+const DEFAULT_PROMPT_PACK = "default"
+
+// A set of prompts with some metadata, so hosts can pick what fits their
+// group. Additional packs are loaded from the -prompt-packs directory, one
+// JSON file per pack:
+//
+//	{
+//	  "name": "Animals",
+//	  "language": "en",
+//	  "category": "nature",
+//	  "rating": "everyone",
+//	  "prompts": ["A sheep doing silly faces", ...]
+//	}
+//
+// The id of the pack is the file name without extension.
+type PromptPack struct {
+	Id       string        `json:"-"`
+	Name     string        `json:"name"`
+	Language string        `json:"language"`
+	Category string        `json:"category"`
+	Rating   ContentRating `json:"rating"`
+	Prompts  []string      `json:"prompts"`
+}
+
+//go:embed drawing_prompts_the_other_kind_of_drawcalls.txt
+var defaultPromptData []byte
+
+// All available prompt packs by id.
+var promptPacks = map[string]*PromptPack{
+	DEFAULT_PROMPT_PACK: {
+		Id:       DEFAULT_PROMPT_PACK,
+		Name:     "The other kind of drawcalls",
+		Language: "en",
+		Category: "mixed",
+		Rating:   CONTENT_RATING_TEEN,
+		Prompts:  cleanPrompts(strings.Split(string(defaultPromptData), "\n")),
+	},
+}
+
+// Used to detect duplicate prompts.
+func promptKey(prompt string) string {
+	return strings.ToLower(strings.Join(strings.Fields(prompt), " "))
+}
+
+// Removes empty and duplicate prompts.
+func cleanPrompts(prompts []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(prompts))
+	for _, prompt := range prompts {
+		prompt = strings.Join(strings.Fields(prompt), " ")
+		if prompt == "" || seen[promptKey(prompt)] {
+			continue
+		}
+		seen[promptKey(prompt)] = true
+		result = append(result, prompt)
+	}
+	return result
+}
+
+func loadPromptPack(path string) (*PromptPack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pack := &PromptPack{
+		Id: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+	}
+	err = json.Unmarshal(data, pack)
+	if err != nil {
+		return nil, err
+	}
+	pack.Prompts = cleanPrompts(pack.Prompts)
+
+	if pack.Name == "" || pack.Language == "" {
+		return nil, fmt.Errorf("name and language are required")
+	}
+
+	valid_rating := false
+	for _, rating := range ALL_CONTENT_RATING_ITEMS {
+		if pack.Rating == rating {
+			valid_rating = true
+		}
+	}
+	if !valid_rating {
+		return nil, fmt.Errorf("unknown content rating %q", pack.Rating)
+	}
+
+	if len(pack.Prompts) < LIMIT_PROMPT_OPTIONS {
+		return nil, fmt.Errorf("at least %d prompts are required", LIMIT_PROMPT_OPTIONS)
+	}
+
+	return pack, nil
+}
+
+// Loads all prompt packs from the directory.
+func loadPromptPacks(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		pack, err := loadPromptPack(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if _, ok := promptPacks[pack.Id]; ok {
+			return fmt.Errorf("%s: duplicate prompt pack id %q", path, pack.Id)
+		}
+
+		promptPacks[pack.Id] = pack
+		log.Println("Loaded prompt pack", pack.Id, "with", len(pack.Prompts), "prompts")
+	}
+	return nil
+}
+
+// Lists the available prompt packs sorted by id.
+func listPromptPacks() []PromptPackInfo {
+	infos := make([]PromptPackInfo, 0, len(promptPacks))
+	for _, pack := range promptPacks {
+		infos = append(infos, PromptPackInfo{
+			Id:       pack.Id,
+			Name:     pack.Name,
+			Language: pack.Language,
+			Category: pack.Category,
+			Rating:   pack.Rating,
+			Prompts:  len(pack.Prompts),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Id < infos[j].Id
+	})
+	return infos
+}
+
+// Checks if one of the selected packs contains the prompt.
+func (session *Session) isPackPrompt(prompt string) bool {
+	key := promptKey(prompt)
+	for _, id := range session.Settings.PromptPacks {
+		for _, pack_prompt := range promptPacks[id].Prompts {
+			if promptKey(pack_prompt) == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Collects the prompts of the selected packs the session hasn't seen yet.
+// Prompts a player submitted as well are left out, so they can't be offered
+// twice and always count as the player's.
+func (session *Session) promptPool() []string {
+	seen := make(map[string]bool)
+	pool := make([]string, 0)
+	for _, id := range session.Settings.PromptPacks {
+		for _, prompt := range promptPacks[id].Prompts {
+			key := promptKey(prompt)
+			if seen[key] || session.seenPrompts[key] || session.customPromptAuthors[key] != nil {
+				continue
+			}
+			seen[key] = true
+			pool = append(pool, prompt)
+		}
+	}
+	return pool
+}
+
+// Selects the prompts offered for the next painting of `painters`. A session
+// only sees each prompt once, until all prompts of the selected packs were
+// used. Custom prompts of the other players are preferred, but there's always
+// at least one prompt from the packs.
+func (session *Session) selectPrompts(rng *mrand.Rand, count int, painters []*Player) []string {
+	custom := make([]string, 0)
+	for author, prompts := range session.customPrompts {
+		if !containsPlayer(painters, author) {
+			custom = append(custom, prompts...)
+		}
+	}
+	if len(custom) > count-1 {
+		custom = nElementsFrom(rng, custom, count-1)
+	}
+
+	pool := session.promptPool()
+	if len(pool) < count-len(custom) {
+		session.ServerPrint("All prompts were used, starting over")
+		session.seenPrompts = make(map[string]bool)
+		pool = session.promptPool()
+	}
+
+	prompts := append(custom, nElementsFrom(rng, pool, count-len(custom))...)
+	rng.Shuffle(len(prompts), func(i, j int) {
+		prompts[i], prompts[j] = prompts[j], prompts[i]
+	})
+
+	session.offeredPromptAuthors = make(map[string]*Player)
+	for _, prompt := range prompts {
+		session.seenPrompts[promptKey(prompt)] = true
+		session.removeCustomPrompt(prompt)
+	}
+	return prompts
+}
+
+// Returns the player that submitted one of the offered prompts, nil if it's
+// from a pack.
+func (session *Session) promptAuthor(prompt string) *Player {
+	return session.offeredPromptAuthors[promptKey(prompt)]
+}
+
+// Moves an offered prompt out of the submitted ones, so it can be submitted
+// again later.
+func (session *Session) removeCustomPrompt(prompt string) {
+	key := promptKey(prompt)
+	author := session.customPromptAuthors[key]
+	if author == nil {
+		return
+	}
+	delete(session.customPromptAuthors, key)
+	session.offeredPromptAuthors[key] = author
+
+	prompts := session.customPrompts[author]
+	for i, p := range prompts {
+		if p == prompt {
+			session.customPrompts[author] = append(prompts[:i], prompts[i+1:]...)
+			break
+		}
+	}
+}
+
+// Forgets the prompts of a player that left.
+func (session *Session) removeCustomPrompts(player *Player) {
+	delete(session.customPrompts, player)
+	for key, author := range session.customPromptAuthors {
+		if author == player {
+			delete(session.customPromptAuthors, key)
+		}
+	}
+	for key, author := range session.offeredPromptAuthors {
+		if author == player {
+			delete(session.offeredPromptAuthors, key)
+		}
+	}
+}
+
+func (session *Session) sendSubmittedPrompts(player *Player) {
+	player.Send(&SubmittedPromptsEvent{
+		Prompts: session.customPrompts[player],
+	})
+}
+
+// Checks a custom prompt. Returns the cleaned prompt or the reason why it
+// can't be used.
+func (session *Session) checkCustomPrompt(author *Player, raw string) (string, string) {
+	prompt := cleanChatMessage(raw)
+
+	length := len(splitGraphemes(prompt))
+	if length < LIMIT_MIN_PROMPT_LEN {
+		return "", TEXT_ERROR_PROMPT_TOO_SHORT
+	}
+	if length > LIMIT_MAX_PROMPT_LEN {
+		return "", TEXT_ERROR_PROMPT_TOO_LONG
+	}
+
+	if chatFilter(prompt) != prompt {
+		return "", TEXT_ERROR_PROMPT_BLOCKED
+	}
+
+	if len(session.customPrompts[author]) >= LIMIT_CUSTOM_PROMPTS {
+		return "", TEXT_ERROR_TOO_MANY_PROMPTS
+	}
+
+	if _, ok := session.customPromptAuthors[promptKey(prompt)]; ok {
+		return "", TEXT_ERROR_DUPLICATE_PROMPT
+	}
+	if session.isPackPrompt(prompt) {
+		return "", TEXT_ERROR_PACK_PROMPT
+	}
+
+	return prompt, ""
+}
+
+func (session *Session) handleSubmitPrompt(pmsg PlayerMessage, msg *SubmitPromptCommand) {
+	prompt, reason := session.checkCustomPrompt(pmsg.Player, msg.Prompt)
+	if reason != "" {
+		pmsg.Player.Send(&PopUpEvent{
+			Message:  reason,
+			Duration: TIME_POPUP_DURATION_MS,
+		})
+		return
+	}
+
+	session.ServerPrint("Player ", pmsg.Player.NickName, " submitted the prompt ", prompt)
+	session.customPrompts[pmsg.Player] = append(session.customPrompts[pmsg.Player], prompt)
+	session.customPromptAuthors[promptKey(prompt)] = pmsg.Player
+
+	session.sendSubmittedPrompts(pmsg.Player)
+}
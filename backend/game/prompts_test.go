@@ -0,0 +1,80 @@
+package game
+
+import (
+	mrand "math/rand"
+	"strings"
+	"testing"
+)
+
+func createPromptTestSession() *Session {
+	return &Session{
+		Settings:             defaultSessionSettings(),
+		seenPrompts:          make(map[string]bool),
+		customPrompts:        make(map[*Player][]string),
+		customPromptAuthors:  make(map[string]*Player),
+		offeredPromptAuthors: make(map[string]*Player),
+	}
+}
+
+func TestCustomPromptFromPack(t *testing.T) {
+	session := createPromptTestSession()
+	author := CreatePlayer(&botTransport{done: make(chan struct{})})
+	painter := CreatePlayer(&botTransport{done: make(chan struct{})})
+	pack_prompt := promptPacks[DEFAULT_PROMPT_PACK].Prompts[0]
+
+	if _, reason := session.checkCustomPrompt(author, strings.ToUpper(pack_prompt)); reason != TEXT_ERROR_PACK_PROMPT {
+		t.Errorf("submitting a pack prompt: got reason %q, want %q", reason, TEXT_ERROR_PACK_PROMPT)
+	}
+
+	// Submitted while the prompt packs were different:
+	session.customPrompts[author] = []string{pack_prompt}
+	session.customPromptAuthors[promptKey(pack_prompt)] = author
+
+	for _, prompt := range session.promptPool() {
+		if promptKey(prompt) == promptKey(pack_prompt) {
+			t.Fatalf("pool contains the submitted prompt %q", prompt)
+		}
+	}
+
+	prompts := session.selectPrompts(mrand.New(mrand.NewSource(1)), LIMIT_PROMPT_OPTIONS, []*Player{painter})
+	count := 0
+	for _, prompt := range prompts {
+		if promptKey(prompt) == promptKey(pack_prompt) {
+			count += 1
+		}
+	}
+	if count != 1 {
+		t.Errorf("prompt offered %d times in %q, want once", count, prompts)
+	}
+	if got := session.promptAuthor(pack_prompt); got != author {
+		t.Errorf("promptAuthor(%q) = %v, want the submitter", pack_prompt, got)
+	}
+}
+
+func TestCustomPromptSubmittedAgain(t *testing.T) {
+	session := createPromptTestSession()
+	author := CreatePlayer(&botTransport{done: make(chan struct{})})
+	other := CreatePlayer(&botTransport{done: make(chan struct{})})
+	painter := CreatePlayer(&botTransport{done: make(chan struct{})})
+	custom := "A giraffe on roller skates"
+
+	session.handleSubmitPrompt(PlayerMessage{Player: author}, &SubmitPromptCommand{Prompt: custom})
+	if _, reason := session.checkCustomPrompt(other, custom); reason != TEXT_ERROR_DUPLICATE_PROMPT {
+		t.Errorf("submitting a pending prompt: got reason %q, want %q", reason, TEXT_ERROR_DUPLICATE_PROMPT)
+	}
+
+	session.selectPrompts(mrand.New(mrand.NewSource(1)), LIMIT_PROMPT_OPTIONS, []*Player{painter})
+	if got := session.promptAuthor(custom); got != author {
+		t.Errorf("promptAuthor(%q) = %v, want the submitter", custom, got)
+	}
+
+	if _, reason := session.checkCustomPrompt(other, custom); reason != "" {
+		t.Errorf("submitting an offered prompt again: got reason %q", reason)
+	}
+
+	// The next painting only has prompts from the packs:
+	session.selectPrompts(mrand.New(mrand.NewSource(2)), LIMIT_PROMPT_OPTIONS, []*Player{painter})
+	if got := session.promptAuthor(custom); got != nil {
+		t.Errorf("promptAuthor(%q) = %v after the next selection, want nil", custom, got)
+	}
+}
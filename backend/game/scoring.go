@@ -0,0 +1,220 @@
+package game
+
+import (
+	mrand "math/rand"
+	"sort"
+)
+
+// Points of a player in the current match, by what they were earned for.
+type scoreCard struct {
+	painting float32 // average rating of their painting
+	trolling float32 // troll effects used on the painters
+	stickers float32 // votes for their stickers
+	guessing float32 // guessed prompts, or prompts others guessed for painters
+}
+
+func (card *scoreCard) total() float32 {
+	return card.painting + card.trolling + card.stickers + card.guessing
+}
+
+// Returns the score card of the player in the current match.
+func (session *Session) scoreCard(player *Player) *scoreCard {
+	card, ok := session.scores[player]
+	if !ok {
+		card = &scoreCard{}
+		session.scores[player] = card
+	}
+	return card
+}
+
+// Finds a player of the running match by id, even if they left already.
+func (session *Session) findMatchPlayer(id string) *Player {
+	for _, player := range session.matchPlayers {
+		if player.Id == id {
+			return player
+		}
+	}
+	return nil
+}
+
+// Ranks all players of the match by their total points. Players with the
+// same points share a rank, otherwise the join order is kept.
+func (session *Session) leaderboard() []LeaderboardEntry {
+	entries := make([]LeaderboardEntry, len(session.matchPlayers))
+	for i, player := range session.matchPlayers {
+		card := session.scoreCard(player)
+		entries[i] = LeaderboardEntry{
+			Player:   session.playerInfo(player),
+			Total:    card.total(),
+			Painting: card.painting,
+			Trolling: card.trolling,
+			Stickers: card.stickers,
+			Guessing: card.guessing,
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Total > entries[j].Total
+	})
+
+	for i := range entries {
+		if i > 0 && entries[i].Total == entries[i-1].Total {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	return entries
+}
+
+// Results of a player over all matches since the scoreboard was reset.
+type standing struct {
+	matches int
+	wins    int
+	stars   float32
+	best    *Painting // nil until one of their paintings was rated
+}
+
+// Adds the results of the finished match to the scoreboard. In team mode,
+// all members of the best teams win. Nobody wins a match in which nobody
+// scored. Players that left during the match don't get a standing.
+func (session *Session) recordMatch(leaderboard []LeaderboardEntry, teams []TeamEntry, results []gameRoundResult) {
+	session.matchesPlayed += 1
+
+	winners := make(map[string]bool)
+	for _, entry := range leaderboard {
+		if len(teams) == 0 && entry.Rank == 1 && entry.Total > 0 {
+			winners[entry.Player.Id] = true
+		}
+	}
+	for _, team := range teams {
+		for _, member := range team.Members {
+			if team.Rank == 1 && team.Total > 0 {
+				winners[member.Id] = true
+			}
+		}
+	}
+
+	for _, entry := range leaderboard {
+		player := session.findMatchPlayer(entry.Player.Id)
+		if player == nil || !session.hasPlayer(player) {
+			continue
+		}
+
+		record := session.standing(player)
+		record.matches += 1
+		if winners[player.Id] {
+			record.wins += 1
+		}
+	}
+
+	for i := range results {
+		for _, painter := range results[i].painters {
+			if !session.hasPlayer(painter) {
+				continue
+			}
+
+			record := session.standing(painter)
+			painting := results[i].painting
+			record.stars += painting.Score
+			if painting.Votes > 0 && (record.best == nil || painting.Score > record.best.Score) {
+				record.best = &painting
+			}
+		}
+	}
+}
+
+func (session *Session) standing(player *Player) *standing {
+	record, ok := session.standings[player]
+	if !ok {
+		record = &standing{}
+		session.standings[player] = record
+	}
+	return record
+}
+
+// Ranks all players in the session by their wins, then by their stars.
+func (session *Session) scoreboard() []ScoreboardEntry {
+	entries := make([]ScoreboardEntry, len(session.Players))
+	for i, player := range session.Players {
+		record := session.standing(player)
+		entries[i] = ScoreboardEntry{
+			Player:       session.playerInfo(player),
+			Matches:      record.matches,
+			Wins:         record.wins,
+			Stars:        record.stars,
+			BestPainting: record.best,
+		}
+	}
+
+	better := func(a *ScoreboardEntry, b *ScoreboardEntry) bool {
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		return a.Stars > b.Stars
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return better(&entries[i], &entries[j])
+	})
+
+	for i := range entries {
+		if i > 0 && !better(&entries[i-1], &entries[i]) {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	return entries
+}
+
+func (session *Session) scoreboardEvent() *ScoreboardEvent {
+	return &ScoreboardEvent{
+		Matches: session.matchesPlayed,
+		Entries: session.scoreboard(),
+	}
+}
+
+func (session *Session) handleResetScoreboard(pmsg PlayerMessage) {
+	if pmsg.Player != session.HostPlayer {
+		session.ServerPrint("Player ", pmsg.Player.NickName, " tried to reset the scoreboard. BAD BOY!")
+		return
+	}
+
+	session.ServerPrint("Scoreboard reset after ", session.matchesPlayed, " matches")
+	session.matchesPlayed = 0
+	session.standings = make(map[*Player]*standing)
+	session.Broadcast(session.scoreboardEvent())
+}
+
+// Selects the paintings that are rated at the end of the match, at most
+// `limit`. Every team gets one painting rated before a team gets a second
+// one. Returns the indices in the order the paintings were made.
+func selectRatedResults(rng *mrand.Rand, results []gameRoundResult, limit int) []int {
+	order := rng.Perm(len(results))
+
+	selected := make([]int, 0, limit)
+	taken := make(map[int]bool)
+	painted := make(map[*Player]bool)
+
+	// First pass: one painting per team, second pass: fill up.
+	for pass := 0; pass < 2; pass++ {
+		for _, index := range order {
+			if len(selected) >= limit || taken[index] {
+				continue
+			}
+			if pass == 0 && painted[results[index].painters[0]] {
+				continue
+			}
+			selected = append(selected, index)
+			taken[index] = true
+			for _, painter := range results[index].painters {
+				painted[painter] = true
+			}
+		}
+	}
+
+	sort.Ints(selected)
+	return selected
+}
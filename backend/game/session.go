@@ -1,11 +1,14 @@
 package game
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
 	"log"
-	"math/rand"
+	mrand "math/rand"
+	"sync"
 	"time"
-	"unsafe"
 
 	"random-projects.net/crayos-backend/meta"
 )
@@ -17,6 +20,25 @@ type PlayerMessage struct {
 
 type SessionFlags struct {
 	Joinable bool
+	Public   bool // the session is listed in the lobby browser
+}
+
+// Options chosen by the host when creating a session.
+type SessionOptions struct {
+	Public        bool
+	RequireSecret bool
+	Password      string // players must enter this password to join, empty if none is required
+}
+
+// A request of a player to join a session.
+type JoinRequest struct {
+	Player     *Player
+	JoinSecret string
+	Password   string
+
+	// If set, receives the reason why joining failed or "" on success
+	// instead of notifying the player.
+	Result chan<- string
 }
 
 type Session struct {
@@ -24,17 +46,40 @@ type Session struct {
 
 	Flags SessionFlags
 
+	Settings SessionSettings
+
 	HostPlayer *Player
 
-	Players map[*Player]bool
+	// All players in the session in the order they joined.
+	Players []*Player
 
 	// Channels:
 	InboundDataChan chan PlayerMessage
-	JoinChan        chan *Player // receives players that have joined the session
-	LeaveChan       chan *Player // receives players that have left  the session
+	JoinChan        chan JoinRequest // receives players that want to join the session
+	LeaveChan       chan *Player     // receives players that have left  the session
+	done            chan struct{}    // closed when the session stopped
 
 	// Internals:
-	startupTime int64
+	startupTime   int64
+	joinSecret    string                 // if not empty, players must know this secret to join
+	password      string                 // if not empty, players must enter this password to join
+	matchPlayers  []*Player              // players taking part in the running match, nil in the lobby
+	matchTeams    [][]*Player            // painting teams of the running match, nil without team mode
+	readyPlayers  map[*Player]bool       // players in the lobby that want to start the match
+	scores        map[*Player]*scoreCard // points of the players in the current or last match
+	standings     map[*Player]*standing  // results of all matches since the scoreboard was reset
+	matchesPlayed int                    // matches since the scoreboard was reset
+	chatRules     chatRules              // what players may say in the current phase
+	chatBudgets   map[*Player]*chatBudget
+	seenPrompts   map[string]bool      // prompts that were already offered in this session
+	effectEnds    map[Effect]time.Time // when the effects triggered in the current painting end
+	activeEffects map[Effect]bool      // effects the painter is suffering from right now
+
+	customPrompts        map[*Player][]string // prompts submitted by the players that weren't offered yet
+	customPromptAuthors  map[string]*Player   // authors of these custom prompts by promptKey
+	offeredPromptAuthors map[string]*Player   // authors of the custom prompts offered for the current painting
+
+	snapshot sessionSnapshot // guarded by sessionsMu
 }
 
 type Role int
@@ -44,34 +89,75 @@ const (
 	ROLE_TROLL   Role = 1
 )
 
+// Guards `sessions` and the snapshots of all sessions.
+var sessionsMu sync.Mutex
 var sessions = map[string]*Session{}
 
 func SetDebugSession(session *Session) {
 	if !*meta.DEBUG_MODE {
 		log.Fatalln("Only allowed in debug mode!")
 	}
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
 	sessions["0xDEADBEEF"] = session
 }
 
-func CreateSession(player *Player) *Session {
+func defaultSessionSettings() SessionSettings {
+	return SessionSettings{
+		MaxPlayers:    LIMIT_MAX_PLAYERS,
+		PaintingTime:  TIME_GAME_PAINTING_S,
+		Mode:          GAME_MODE_CLASSIC,
+		TurnOrder:     TURN_ORDER_ROTATION,
+		PaintTurns:    1,
+		Rounds:        4,
+		Stickers:      1,
+		PromptPacks:   []string{DEFAULT_PROMPT_PACK},
+		StickerPacks:  []string{DEFAULT_STICKER_PACK},
+		BackdropPacks: []string{DEFAULT_BACKDROP_PACK},
+		Effects:       append([]Effect{}, ALL_EFFECT_ITEMS...),
+	}
+}
+
+func CreateSession(player *Player, options SessionOptions) *Session {
 	session := &Session{
 		HostPlayer: player,
-		Players:    make(map[*Player]bool),
+		Players:    make([]*Player, 0, LIMIT_MAX_PLAYERS),
 
 		InboundDataChan: make(chan PlayerMessage, 256), // buffered channel
-		JoinChan:        make(chan *Player),            // synchronous channels
+		JoinChan:        make(chan JoinRequest),        // synchronous channels
 		LeaveChan:       make(chan *Player),            // synchronous channels
+		done:            make(chan struct{}),
 
 		Flags: SessionFlags{
 			Joinable: true,
+			Public:   options.Public,
 		},
-
-		startupTime: meta.Timestamp(),
+		Settings: defaultSessionSettings(),
+
+		startupTime:  meta.Timestamp(),
+		readyPlayers: make(map[*Player]bool),
+		scores:       make(map[*Player]*scoreCard),
+		standings:    make(map[*Player]*standing),
+		chatBudgets:  make(map[*Player]*chatBudget),
+		seenPrompts:  make(map[string]bool),
+
+		customPrompts:        make(map[*Player][]string),
+		customPromptAuthors:  make(map[string]*Player),
+		offeredPromptAuthors: make(map[string]*Player),
 	}
 	session.Id = fmt.Sprintf("%p", session)
 
+	if options.RequireSecret {
+		session.joinSecret = createJoinSecret()
+	}
+	session.password = options.Password
+
 	if player != nil {
-		session.AddPlayer(player)
+		session.AddPlayer(JoinRequest{
+			Player:     player,
+			JoinSecret: session.joinSecret,
+			Password:   session.password,
+		})
 	} else if !*meta.DEBUG_MODE {
 		log.Fatalln("Invalid parameter: Session requires a player in non-debug mode")
 	}
@@ -82,13 +168,27 @@ func CreateSession(player *Player) *Session {
 
 	// Register session
 	session.ServerPrint("Created")
+	sessionsMu.Lock()
 	sessions[session.Id] = session
+	sessionsMu.Unlock()
+
+	session.publishSnapshot()
 
 	return session
 }
 
+func createJoinSecret() string {
+	var secret [16]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		log.Fatalln("failed to create join secret: ", err)
+	}
+	return hex.EncodeToString(secret[:])
+}
+
 func FindSession(id string) *Session {
-	// TODO(fqu): Should be mutex checked
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
 	session, ok := sessions[id]
 	if ok {
 		return session
@@ -98,32 +198,112 @@ func FindSession(id string) *Session {
 }
 
 func (session *Session) Destroy() {
-	delete(sessions, session.Id)
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	for id, s := range sessions {
+		if s == session {
+			delete(sessions, id) // also removes the debug alias
+		}
+	}
+}
+
+// Passes the request to the session loop. Returns false if the session
+// has already stopped.
+func (session *Session) RequestJoin(request JoinRequest) bool {
+	select {
+	case session.JoinChan <- request:
+		return true
+	case <-session.done:
+		return false
+	}
+}
+
+// Tells the session loop that the player has left.
+func (session *Session) RequestLeave(player *Player) {
+	select {
+	case session.LeaveChan <- player:
+	case <-session.done:
+	}
 }
 
-func (session *Session) AddPlayer(new *Player) bool {
+// Checks if the player may join. Returns the reason if not.
+func (session *Session) checkJoin(request JoinRequest) string {
+	if session.joinSecret != "" && subtle.ConstantTimeCompare([]byte(request.JoinSecret), []byte(session.joinSecret)) != 1 {
+		session.ServerPrint("Player ", request.Player.NickName, " used a bad join secret")
+		return TEXT_ERROR_BAD_SECRET
+	}
+
+	if !session.checkPassword(request.Password) {
+		session.ServerPrint("Player ", request.Player.NickName, " used a wrong password")
+		return TEXT_ERROR_WRONG_PASSWORD
+	}
 
 	if !session.Flags.Joinable {
+		return TEXT_ERROR_SESSION_ONLINE
+	}
+
+	if len(session.Players) >= session.Settings.MaxPlayers {
+		return TEXT_ERROR_SESSION_FULL
+	}
+
+	return ""
+}
+
+func (session *Session) AddPlayer(request JoinRequest) bool {
+	new := request.Player
+
+	reason := session.checkJoin(request)
+	if request.Result != nil {
+		request.Result <- reason
+	} else if reason != "" {
 		new.Send(&JoinSessionFailedEvent{
-			Reason: TEXT_ERROR_SESSION_ONLINE,
+			Reason: reason,
 		})
+	}
+	if reason != "" {
 		return false
 	}
 
-	if len(session.Players) > LIMIT_MAX_PLAYERS {
-		new.Send(&JoinSessionFailedEvent{
-			Reason: TEXT_ERROR_SESSION_FULL,
+	// Players must be distinguishable, so duplicate names or names posing
+	// as someone else get a number:
+	nick := new.NickName
+	for n := 2; session.isNickNameTaken(nick); n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		nick = truncateNickName(new.NickName, LIMIT_MAX_NICKNAME_LEN-len(suffix)) + suffix
+	}
+	if nick != new.NickName {
+		session.ServerPrint("Player ", new.NickName, " is renamed to ", nick)
+		new.NickName = nick
+		new.Send(&PopUpEvent{
+			Message:  TEXT_POPUP_RENAMED + nick,
+			Duration: TIME_POPUP_DURATION_MS,
 		})
-		return false
 	}
 
 	session.ServerPrint("Player ", new.NickName, " joined")
 
+	new.mu.Lock()
 	new.Session = session
-	session.Players[new] = true
+	new.mu.Unlock()
+	session.Players = append(session.Players, new)
 
 	new.Send(&EnterSessionEvent{
-		SessionId: session.Id,
+		SessionId:  session.Id,
+		JoinSecret: session.joinSecret,
+		PlayerId:   new.Id,
+	})
+	new.Send(&SettingsChangedEvent{
+		Settings: session.Settings,
+	})
+	new.Send(&PromptPacksEvent{
+		Packs: listPromptPacks(),
+	})
+	new.Send(&StickerPacksEvent{
+		Packs: listStickerPacks(),
+	})
+	new.Send(&BackdropPacksEvent{
+		Packs: listBackdropPacks(),
 	})
 
 	session.BroadcastPlayers(new, nil)
@@ -131,46 +311,139 @@ func (session *Session) AddPlayer(new *Player) bool {
 	new.Send(&ChangeGameViewEvent{
 		View: GAME_VIEW_LOBBY,
 	})
+	if session.matchPlayers == nil {
+		session.Broadcast(session.scoreboardEvent())
+	}
 	return true
 }
 
+// Removes a player that left the session and passes the host role on if
+// necessary.
+func (session *Session) RemovePlayer(old *Player) {
+	for i, player := range session.Players {
+		if player == old {
+			session.Players = append(session.Players[:i], session.Players[i+1:]...)
+			break
+		}
+	}
+	delete(session.readyPlayers, old)
+	delete(session.chatBudgets, old)
+	delete(session.standings, old)
+	session.removeCustomPrompts(old)
+
+	if session.HostPlayer == old && len(session.Players) > 0 {
+		// Bots can't host, unless nobody else is left:
+		session.HostPlayer = session.Players[0]
+		for _, player := range session.Players {
+			if !player.IsBot() {
+				session.HostPlayer = player
+				break
+			}
+		}
+		session.ServerPrint("Player ", session.HostPlayer.NickName, " is the new host")
+	}
+
+	// Bots don't play on their own:
+	if !session.hasHumans() {
+		for _, player := range session.Players {
+			player.transport.Close()
+		}
+	}
+
+	if session.matchPlayers == nil {
+		session.Broadcast(session.scoreboardEvent())
+	}
+}
+
+// Checks if the nickname looks like the name of a player in the session.
+func (session *Session) isNickNameTaken(nick string) bool {
+	skeleton := nickNameSkeleton(nick)
+	for _, player := range session.Players {
+		if nickNameSkeleton(player.NickName) == skeleton {
+			return true
+		}
+	}
+	return false
+}
+
+func (session *Session) hasPlayer(player *Player) bool {
+	for _, p := range session.Players {
+		if p == player {
+			return true
+		}
+	}
+	return false
+}
+
 func (session *Session) Broadcast(msg Message) {
-	for player := range session.Players {
+	for _, player := range session.Players {
 		player.Send(msg)
 	}
 }
 
-func (session *Session) BroadcastExcept(msg Message, except *Player) {
-	for player := range session.Players {
-		if player != except {
+func (session *Session) BroadcastExcept(msg Message, except ...*Player) {
+	for _, player := range session.Players {
+		if !containsPlayer(except, player) {
 			player.Send(msg)
 		}
 	}
 }
 
-func (session *Session) BroadcastPlayers(added_player *Player, removed_player *Player) {
-	nicknames := make([]string, len(session.Players))
+func containsPlayer(players []*Player, player *Player) bool {
+	for _, p := range players {
+		if p == player {
+			return true
+		}
+	}
+	return false
+}
+
+func (session *Session) playerInfo(player *Player) PlayerInfo {
+	return PlayerInfo{
+		Id:        player.Id,
+		Name:      player.NickName,
+		Host:      player == session.HostPlayer,
+		Ready:     session.readyPlayers[player],
+		Connected: session.hasPlayer(player),
+		Bot:       player.IsBot(),
+		Score:     session.scoreCard(player).total(),
+	}
+}
 
-	i := 0
-	for k := range session.Players {
-		nicknames[i] = k.NickName
-		i++
+// Lists the players in join order. During a match, players that left are
+// still listed as disconnected.
+func (session *Session) playerInfos() []PlayerInfo {
+	players := session.Players
+	if session.matchPlayers != nil {
+		players = session.matchPlayers
 	}
 
+	infos := make([]PlayerInfo, len(players))
+	for i, player := range players {
+		infos[i] = session.playerInfo(player)
+	}
+	return infos
+}
+
+func (session *Session) BroadcastPlayers(added_player *Player, removed_player *Player) {
 	evt := PlayersChangedEvent{
-		Players:       nicknames,
+		Players:       session.playerInfos(),
 		AddedPlayer:   nil,
 		RemovedPlayer: nil,
 	}
 
 	if added_player != nil {
-		evt.AddedPlayer = &added_player.NickName
+		info := session.playerInfo(added_player)
+		evt.AddedPlayer = &info
 	}
 	if removed_player != nil {
-		evt.RemovedPlayer = &removed_player.NickName
+		info := session.playerInfo(removed_player)
+		evt.RemovedPlayer = &info
 	}
 
 	session.Broadcast(&evt)
+
+	session.publishSnapshot()
 }
 
 type NotifyTimeout struct {
@@ -218,20 +491,22 @@ func (session *Session) PumpEvents(timer gameTimer) *PlayerMessage {
 	for *meta.DEBUG_MODE || len(session.Players) > 0 {
 		select {
 		case pmsg := <-session.InboundDataChan:
-			return &pmsg
+			if !session.handleSessionCommand(pmsg) {
+				return &pmsg
+			}
 
-		case new := <-session.JoinChan:
-			if session.AddPlayer(new) {
+		case request := <-session.JoinChan:
+			if session.AddPlayer(request) {
 				return &PlayerMessage{
 					Message: &NotifyPlayerJoined{},
-					Player:  new,
+					Player:  request.Player,
 				}
 			}
 
 		case old := <-session.LeaveChan:
 
 			session.ServerPrint("Player ", old.NickName, " left")
-			delete(session.Players, old)
+			session.RemovePlayer(old)
 
 			session.BroadcastPlayers(nil, old)
 
@@ -251,20 +526,66 @@ func (session *Session) PumpEvents(timer gameTimer) *PlayerMessage {
 	return nil
 }
 
-func broadcastPlayerReadyState(s *Session, m playerSet) {
-	s.ServerPrint("Sending PlayerReadyState", m)
-	readyMap := make(map[string]bool)
-	for p, b := range m.items {
-		readyMap[p.NickName] = b.value
+// Handles the commands that are valid in every phase of the session.
+// Returns false if the command must be handled by the current phase.
+func (session *Session) handleSessionCommand(pmsg PlayerMessage) bool {
+	switch msg := pmsg.Message.(type) {
+	case *CreateInviteCommand:
+		session.handleCreateInvite(pmsg, msg)
+
+	case *ChatCommand:
+		session.handleChat(pmsg, msg)
+
+	case *ReactCommand:
+		session.handleReact(pmsg, msg)
+
+	default:
+		return false
 	}
+	return true
+}
+
+func broadcastPlayerReadyState(s *Session) {
+	s.ServerPrint("Sending PlayerReadyState", s.readyPlayers)
 	s.Broadcast(&PlayerReadyChangedEvent{
-		Players: readyMap,
+		Players: s.playerInfos(),
 	})
 }
 
+func (session *Session) allPlayersReady() bool {
+	for _, player := range session.Players {
+		if !session.readyPlayers[player] {
+			return false
+		}
+	}
+	return true
+}
+
 type gameRoundResult struct {
-	painting    Painting
-	totalPoints int
+	painting Painting
+	painters []*Player       // the team that painted it
+	ratings  map[*Player]int // stars given by the other players
+	rated    bool            // the painting was shown in the rating phase
+}
+
+// Average of the ratings the painting got, 0 if nobody rated it.
+func (result *gameRoundResult) averageRating() float32 {
+	if len(result.ratings) == 0 {
+		return 0
+	}
+	total := 0
+	for _, stars := range result.ratings {
+		total += stars
+	}
+	return float32(total) / float32(len(result.ratings))
+}
+
+var RATING_OPTIONS = map[string]int{
+	"star1": 1,
+	"star2": 2,
+	"star3": 3,
+	"star4": 4,
+	"star5": 5,
 }
 
 func (evt *ChangeGameViewEvent) RemoveVote() {
@@ -311,7 +632,7 @@ func (session *Session) Announce(text string, duration time.Duration) {
 }
 
 // Takes `count` random elements from `source` based on `rng`.
-func nElementsFrom(rng *rand.Rand, source []string, count int) []string {
+func nElementsFrom(rng *mrand.Rand, source []string, count int) []string {
 	items := make([]string, len(source))
 	copy(items, source)
 	rng.Shuffle(len(items), func(i, j int) {
@@ -321,10 +642,12 @@ func nElementsFrom(rng *rand.Rand, source []string, count int) []string {
 }
 
 func (session *Session) Run() {
-	random_source := rand.New(rand.NewSource(time.Now().UnixNano()))
+	random_source := mrand.New(mrand.NewSource(time.Now().UnixNano()))
 
 	session.ServerPrint("Started")
 	defer session.ServerPrint("Stopped")
+	defer close(session.done)
+	defer session.Destroy()
 
 	no_timeout := &noTimeoutGameTimer{
 		channel: make(chan time.Time), // pass when no timeout is required
@@ -336,16 +659,24 @@ func (session *Session) Run() {
 		session.DebugPrint("Enter lobby")
 		{
 			session.Flags.Joinable = true
+			session.publishSnapshot()
+			session.matchPlayers = nil
+			session.matchTeams = nil
+			session.readyPlayers = make(map[*Player]bool)
+			session.setChatRules(chatRules{})
 
 			// Show lobby
 			session.Broadcast(&ChangeGameViewEvent{
 				View: GAME_VIEW_LOBBY,
 			})
+			for _, player := range session.Players {
+				session.sendSubmittedPrompts(player)
+			}
+			session.Broadcast(session.scoreboardEvent())
 
-			players_ready := createPlayerSetFromMap(session.Players, nil)
-			for len(session.Players) < 2 || players_ready.any(false) {
+			for len(session.Players) < 2 || !session.allPlayersReady() {
 
-				broadcastPlayerReadyState(session, players_ready)
+				broadcastPlayerReadyState(session)
 
 				pmsg := session.PumpEvents(no_timeout)
 				if pmsg == nil {
@@ -356,20 +687,31 @@ func (session *Session) Run() {
 				case *UserCommand:
 					switch msg.Action {
 					case USER_ACTION_SET_READY:
-						players_ready.add(pmsg.Player)
+						session.readyPlayers[pmsg.Player] = true
 					case USER_ACTION_SET_NOT_READY:
-						players_ready.remove(pmsg.Player)
+						session.readyPlayers[pmsg.Player] = false
 					}
-				case *NotifyPlayerJoined:
-					players_ready.insertNewPlayer(pmsg.Player, false)
 
-				case *NotifyPlayerLeft:
-					players_ready.removePlayer(pmsg.Player)
+				case *ChangeSettingsCommand:
+					session.handleChangeSettings(*pmsg, msg)
+
+				case *SubmitPromptCommand:
+					session.handleSubmitPrompt(*pmsg, msg)
+
+				case *ResetScoreboardCommand:
+					session.handleResetScoreboard(*pmsg)
+
+				case *AddBotCommand:
+					session.handleAddBot(*pmsg)
 
+				case *RemoveBotCommand:
+					session.handleRemoveBot(*pmsg, msg)
 				}
 			}
 
 			session.Flags.Joinable = false
+			session.publishSnapshot()
+			session.readyPlayers = make(map[*Player]bool)
 		}
 
 		session.DebugPrint("Start game")
@@ -378,28 +720,37 @@ func (session *Session) Run() {
 		{
 			// Create a list of players:
 			players := make([]*Player, len(session.Players))
-			{
-				i := 0
-				for p := range session.Players {
-					players[i] = p
-					i += 1
-				}
-			}
+			copy(players, session.Players)
 
-			// create random player order which we will use this round:
-			random_source.Shuffle(len(players), func(i, j int) {
-				players[i], players[j] = players[j], players[i]
-			})
+			session.matchPlayers = append([]*Player{}, session.Players...)
+			guessing := session.Settings.Mode == GAME_MODE_GUESSING
+			session.scores = make(map[*Player]*scoreCard)
+			session.BroadcastPlayers(nil, nil)
 
-			results := make([]gameRoundResult, len(players))
+			// Plan who paints with whom and when:
+			teams := formTeams(random_source, players, session.Settings.Teams)
+			if session.Settings.Teams {
+				session.matchTeams = teams
+			}
+			turns := planTurns(random_source, len(teams), session.Settings)
+			results := make([]gameRoundResult, 0, len(turns))
+			sticker_turns := make(map[*Player]int) // how often each player could sticker
+			used_backdrops := make(map[string]bool)
 
-			// Each player gets their turn:
-			for index, active_painter := range players {
+			// Each team gets their turn:
+			for index, team_index := range turns {
 
 				round_id := fmt.Sprintf("Round %d: ", index+1)
 
+				// Painters that left can't take their turn:
+				active_painters := session.presentMembers(teams[team_index])
+				if len(active_painters) == 0 {
+					session.DebugPrint(round_id, "Skipped, painters have left")
+					continue
+				}
+
 				fmt_context := AnnouncementContext{
-					PainterName: active_painter.NickName,
+					PainterName: teamName(active_painters),
 				}
 
 				session.DebugPrint(round_id, "Initialize")
@@ -407,7 +758,7 @@ func (session *Session) Run() {
 				// Assign roles:
 				player_role := make(map[*Player]Role)
 				for _, player := range players {
-					if player == active_painter {
+					if containsPlayer(active_painters, player) {
 						player_role[player] = ROLE_PAINTER
 					} else {
 						player_role[player] = ROLE_TROLL
@@ -450,20 +801,36 @@ func (session *Session) Run() {
 					}
 				}
 
-				// Select one random background:
-
-				backdrop := ALL_BACKDROP_ITEMS[random_source.Intn(len(ALL_BACKDROP_ITEMS))]
+				// Select one random background, or the ones the trolls vote for:
+				backdrop_count := 1
+				if session.Settings.BackdropVote {
+					backdrop_count = LIMIT_BACKDROP_OPTIONS
+				}
+				backdrop_options := session.selectBackdrops(random_source, backdrop_count, used_backdrops)
+				backdrop := backdrop_options[0] // until the vote is done
+				if len(backdrop_options) < 2 {
+					backdrop_options = []string{}
+				}
+				backdrop_votes := make(map[string]int)
+				backdrop_voted := make(map[*Player]bool)
 
-				prompts := nElementsFrom(random_source, AVAILABLE_PROMPTS, 3)
+				prompts := session.selectPrompts(random_source, LIMIT_PROMPT_OPTIONS, active_painters)
 
-				session.ServerPrint("selected backdrop:", backdrop)
+				session.ServerPrint("selected backdrops: ", backdrop_options, " showing ", backdrop)
 				session.ServerPrint("selected prompts: ", prompts)
 
 				// Tell them what's happening
-				splitAnnounce(
-					TEXT_ANNOUNCE_YOU_ARE_PAINTER.Format(fmt_context),
-					TEXT_ANNOUNCE_YOU_ARE_TROLL.Format(fmt_context),
-				)
+				if guessing {
+					splitAnnounce(
+						TEXT_ANNOUNCE_GUESS_PAINTER.Format(fmt_context),
+						TEXT_ANNOUNCE_GUESS_TROLL.Format(fmt_context),
+					)
+				} else {
+					splitAnnounce(
+						TEXT_ANNOUNCE_YOU_ARE_PAINTER.Format(fmt_context),
+						TEXT_ANNOUNCE_YOU_ARE_TROLL.Format(fmt_context),
+					)
+				}
 
 				// Create prototypes for the views:
 				troll_view := &ChangeGameViewEvent{
@@ -475,6 +842,7 @@ func (session *Session) Run() {
 						Prompt:   "",
 						Stickers: []Sticker{},
 					},
+					BackdropOptions: backdrop_options,
 				}
 				painter_view := &ChangeGameViewEvent{
 					View: GAME_VIEW_ARTSTUDIO_GENERIC,
@@ -487,40 +855,82 @@ func (session *Session) Run() {
 					},
 				}
 
-				// local function to update the roles:
-				updateViews := func() {
-					for _, player := range players {
-						switch player_role[player] {
-						case ROLE_PAINTER:
-							// session.ServerPrint("send view (painter)", player.NickName, painter_view)
-							player.Send(painter_view)
-						case ROLE_TROLL:
-							// session.ServerPrint("send view (troll)", player.NickName, troll_view)
+				// local functions to update the roles:
+				sendView := func(player *Player) {
+					switch player_role[player] {
+					case ROLE_PAINTER:
+						// session.ServerPrint("send view (painter)", player.NickName, painter_view)
+						player.Send(painter_view)
+					case ROLE_TROLL:
+						// session.ServerPrint("send view (troll)", player.NickName, troll_view)
+						if backdrop_voted[player] && len(troll_view.BackdropOptions) > 0 {
+							// Hide the backdrops for the troll that voted:
+							view := *troll_view
+							view.BackdropOptions = []string{}
+							player.Send(&view)
+						} else {
 							player.Send(troll_view)
 						}
 					}
 				}
 
+				updateViews := func() {
+					for _, player := range players {
+						sendView(player)
+					}
+				}
+
 				changeBoth := func(handler func(view *ChangeGameViewEvent)) {
 					handler(troll_view)
 					handler(painter_view)
 				}
 
-				troll_view.SetVote(TEXT_VOTE_PROMPT, prompts)
-				painter_view.RemoveVote()
+				// In guessing mode, only the painter may know the prompt:
+				if guessing {
+					troll_view.View = GAME_VIEW_ARTSTUDIO_GENERIC
+					troll_view.RemoveVote()
+					painter_view.View = GAME_VIEW_PROMPTSELECTION
+					painter_view.SetVote(TEXT_VOTE_PROMPT, prompts)
+				} else {
+					troll_view.SetVote(TEXT_VOTE_PROMPT, prompts)
+					painter_view.RemoveVote()
+				}
+				painter_view.CanSkip = true // until the prompt is selected
 
 				// Now update the views for the players
 				updateViews()
 
-				// Prepare message for trolls to go into "wait for others" state
-				troll_view.View = GAME_VIEW_ARTSTUDIO_GENERIC
-				troll_view.RemoveVote()
+				// Prepare message for voters to go into "wait for others" state
+				changeBoth(func(view *ChangeGameViewEvent) {
+					view.View = GAME_VIEW_ARTSTUDIO_GENERIC
+					view.RemoveVote()
+				})
+
+				// Players that must not learn the prompt options:
+				uninformed := make(map[*Player]bool)
+				for _, player := range players {
+					if (player_role[player] == ROLE_PAINTER) != guessing {
+						uninformed[player] = true
+					}
+				}
 
-				// Phase 1: Trolls vote for a prompt
+				// Phase 1: Trolls vote for a prompt, or the painter selects one in guessing mode
 				session.DebugPrint(round_id, "Prompt voting for trolls starts")
+				session.setChatRules(chatRules{
+					spoilers:   prompts,
+					uninformed: uninformed,
+				})
 				var selected_painting_prompt string
+				skipped := false
 				{
-					prompt_voted := createPlayerSetFromList(players, active_painter)
+					prompt_voted := createPlayerSetFromList(players, active_painters...)
+
+					votingDone := func() bool {
+						if guessing {
+							return prompt_voted.painterSet()
+						}
+						return prompt_voted.allTrollsSet()
+					}
 
 					votes := make([]float32, len(prompts))
 					for i := range votes {
@@ -530,15 +940,26 @@ func (session *Session) Run() {
 
 					vote_end_timer := session.createTimer(TIME_GAME_PROMPTVOTE_S)
 
-					for !vote_end_timer.TimedOut() && !prompt_voted.allTrollsSet() {
+					for !vote_end_timer.TimedOut() && !votingDone() && !skipped {
 						pmsg := session.PumpEvents(vote_end_timer)
 						if pmsg == nil {
 							return
 						}
 
 						switch msg := pmsg.Message.(type) {
+						case *UserCommand:
+							if msg.Action != USER_ACTION_SKIP_TURN {
+								break
+							}
+							if player_role[pmsg.Player] == ROLE_PAINTER {
+								session.ServerPrint("Player ", pmsg.Player.NickName, " skipped their turn")
+								skipped = true
+							} else {
+								session.ServerPrint("player may not skip the turn of the painter. BAD BOY")
+							}
+
 						case *VoteCommand:
-							if pmsg.Player != active_painter {
+							if (player_role[pmsg.Player] == ROLE_PAINTER) == guessing {
 
 								session.ServerPrint("Player ", pmsg.Player.NickName, " voted for", msg)
 
@@ -554,20 +975,51 @@ func (session *Session) Run() {
 
 									prompt_voted.add(pmsg.Player)
 
-									// Hide the options for the troll that voted:
-									pmsg.Player.Send(troll_view)
+									// Hide the options for the player that voted:
+									sendView(pmsg.Player)
 
 								} else {
-									session.ServerPrint("troll tried to vote illegaly. BAD BOY")
+									session.ServerPrint("player tried to vote illegaly. BAD BOY")
 
 								}
 
 							} else {
-								session.ServerPrint("painter tried to vote. BAD BOY")
+								session.ServerPrint("player may not vote for the prompt. BAD BOY")
+							}
+
+						case *VoteBackdropCommand:
+							if player_role[pmsg.Player] != ROLE_TROLL || backdrop_voted[pmsg.Player] {
+								session.ServerPrint("player may not vote for the backdrop. BAD BOY")
+								break
+							}
+							offered := false
+							for _, option := range troll_view.BackdropOptions {
+								if option == msg.Backdrop {
+									offered = true
+								}
+							}
+							if !offered {
+								session.ServerPrint("player tried to vote for an unknown backdrop. BAD BOY")
+								break
 							}
+
+							backdrop_votes[msg.Backdrop] += 1
+							backdrop_voted[pmsg.Player] = true
 						}
 					}
 
+					// The backdrop with the most votes wins, ties are decided randomly:
+					best_backdrop_votes := -1
+					for _, index := range random_source.Perm(len(backdrop_options)) {
+						if votes := backdrop_votes[backdrop_options[index]]; votes > best_backdrop_votes {
+							backdrop = backdrop_options[index]
+							best_backdrop_votes = votes
+						}
+					}
+					if len(backdrop_options) > 0 {
+						session.ServerPrint("Backdrop ", backdrop, " won with ", best_backdrop_votes, " votes")
+					}
+
 					best_prompt_index := 0
 					best_prompt_level := votes[0]
 
@@ -582,11 +1034,27 @@ func (session *Session) Run() {
 
 					session.ServerPrint("Prompt", selected_painting_prompt, "won with", best_prompt_level, "votes")
 				}
+				if skipped {
+					session.Broadcast(&PopUpEvent{
+						Message:  teamName(active_painters) + TEXT_POPUP_TURN_SKIPPED,
+						Duration: TIME_POPUP_DURATION_MS,
+					})
+					continue
+				}
+
+				used_backdrops[backdrop] = true
 
 				changeBoth(func(view *ChangeGameViewEvent) {
 					view.RemoveVote()
+					view.CanSkip = false
 					view.Painting.Prompt = selected_painting_prompt
+					view.Painting.Backdrop = backdrop
+					view.BackdropOptions = []string{}
 				})
+				if guessing {
+					troll_view.Painting.Prompt = ""
+					troll_view.CanGuess = true
+				}
 
 				troll_view.View = GAME_VIEW_ARTSTUDIO_GENERIC
 				painter_view.View = GAME_VIEW_ARTSTUDIO_ACTIVE
@@ -600,18 +1068,39 @@ func (session *Session) Run() {
 
 				// Phase 2:
 				session.DebugPrint(round_id, "Painter is now being tortured")
+				var guess_round *guessRound
+				if guessing {
+					guess_round = createGuessRound(selected_painting_prompt)
+					for _, painter := range active_painters {
+						delete(uninformed, painter)
+					}
+
+					// The author of a custom prompt can't guess it:
+					if author := session.promptAuthor(selected_painting_prompt); author != nil {
+						guess_round.solved[author] = true
+						delete(uninformed, author)
+					}
+					session.setChatRules(chatRules{
+						spoilers:   []string{selected_painting_prompt},
+						uninformed: uninformed,
+					})
+				} else {
+					session.setChatRules(chatRules{})
+				}
 				{
-					// Setup troll order, current troll is always the first one
-					trolls := make([]*Player, len(players)-1)
+					session.effectEnds = make(map[Effect]time.Time)
+					session.activeEffects = make(map[Effect]bool)
+					effect_options := make(map[*Player][]string) // what the acting trolls may choose from
+
+					// Setup troll order, the trolls acting in the current time slice are moved to the back
+					trolls := make([]*Player, 0, len(players))
 
 					{
-						i := 0
 						for _, player := range players {
-							if player == active_painter {
+							if player_role[player] == ROLE_PAINTER {
 								continue
 							}
-							trolls[i] = player
-							i += 1
+							trolls = append(trolls, player)
 						}
 
 						// shuffle troll order:
@@ -620,39 +1109,57 @@ func (session *Session) Run() {
 						})
 					}
 
+					painter_graphics := make(map[*Player]Graphics) // what each painter of the team painted
+
 					next_troll_event := 0
-					troll_did_effect := true // first "troll" always did the effect, so they don't receive a weird warning about being a sleephead
+					acting_trolls := []*Player{}
+					troll_did_effect := make(map[*Player]bool)
+					trolls_per_slice := trollsPerSlice(len(trolls), session.Settings.PaintingTime)
 
 					// Setup session timing:
 
-					round_end_timer := session.createTimer(TIME_GAME_PAINTING_S)
+					round_end_timer := session.createTimer(session.Settings.PaintingTime)
 
 					for !round_end_timer.TimedOut() {
 
 						if next_troll_event <= 0 {
 
-							trolls[0].Send(troll_view) // troll view is "generic empty" here
+							for _, troll := range acting_trolls {
+								troll.Send(troll_view) // troll view is "generic empty" here
 
-							if len(trolls) > 1 && !troll_did_effect {
-								trolls[0].Send(&PopUpEvent{
-									Message:  TEXT_POPUP_MISSED_TROLLING,
-									Duration: TIME_POPUP_DURATION_MS,
-								})
+								if len(trolls) > trolls_per_slice && !troll_did_effect[troll] {
+									troll.Send(&PopUpEvent{
+										Message:  TEXT_POPUP_MISSED_TROLLING,
+										Duration: TIME_POPUP_DURATION_MS,
+									})
+								}
 							}
 
-							// select next troll by doing round-robin scheduling:
-							trolls = append(trolls[1:], trolls[0])
+							// select the next trolls by doing round-robin scheduling:
+							acting_trolls = append([]*Player{}, trolls[:trolls_per_slice]...)
+							trolls = append(trolls[trolls_per_slice:], acting_trolls...)
 
-							vote_effect_view := *troll_view
+							effect_options = make(map[*Player][]string)
+							troll_did_effect = make(map[*Player]bool)
+							for _, troll := range acting_trolls {
+								options := session.selectEffects(random_source, LIMIT_EFFECT_OPTIONS)
 
-							vote_effect_view.SetVote(TEXT_VOTE_EFFECT, *(*[]string)(unsafe.Pointer(&ALL_EFFECT_ITEMS)))
+								if len(options) > 0 {
+									vote_effect_view := *troll_view
 
-							trolls[0].Send(&vote_effect_view) // troll view is "generic empty" here
-							trolls[0].Send(&PopUpEvent{
-								Message:  TEXT_POPUP_START_TROLLING,
-								Duration: TIME_POPUP_DURATION_MS,
-							})
-							troll_did_effect = false
+									vote_effect_view.SetVote(TEXT_VOTE_EFFECT, options)
+
+									troll.Send(&vote_effect_view) // troll view is "generic empty" here
+									troll.Send(&PopUpEvent{
+										Message:  TEXT_POPUP_START_TROLLING,
+										Duration: TIME_POPUP_DURATION_MS,
+									})
+									effect_options[troll] = options
+								} else {
+									// All effects are cooling down, this troll has to sit this one out
+									troll_did_effect[troll] = true
+								}
+							}
 
 							next_troll_event = TIME_GAME_NEXT_TROLLEFFECT_S
 						}
@@ -666,35 +1173,71 @@ func (session *Session) Run() {
 
 						case *NotifyTimeout:
 							next_troll_event -= 1
+							session.expireEffects()
 
 						case *VoteCommand:
-							if pmsg.Player == trolls[0] && !troll_did_effect {
-								// TODO(fqu): validate that msg.Option is actually a legal vote!
-								session.Broadcast(&ChangeToolModifierEvent{
-									Modifier: Effect(msg.Option),
-									Duration: TIME_GAME_TROLL_EFFECT_DURATION_MS,
-								})
-								trolls[0].Send(troll_view) // reset troll to regular view, hide the vote options
-								troll_did_effect = true
+							if containsPlayer(acting_trolls, pmsg.Player) && !troll_did_effect[pmsg.Player] {
+								offered := false
+								for _, option := range effect_options[pmsg.Player] {
+									if msg.Option == option {
+										offered = true
+									}
+								}
+								if !offered {
+									session.ServerPrint("troll tried to use an effect that wasn't offered. BAD BOY!")
+									break
+								}
+
+								// Another troll of this time slice may have been faster:
+								if session.triggerEffect(TROLL_EFFECTS[Effect(msg.Option)]) {
+									session.scoreCard(pmsg.Player).trolling += POINTS_EFFECT_USED
+								}
+								pmsg.Player.Send(troll_view) // reset troll to regular view, hide the vote options
+								troll_did_effect[pmsg.Player] = true
 							} else {
 								session.ServerPrint("someone else tried to harm the painter. BAD BOY!")
 							}
 
 						case *SetPaintingCommand:
-							if pmsg.Player == active_painter {
+							if player_role[pmsg.Player] == ROLE_PAINTER {
 
 								// Keep the state up to date with the painted image:
-								troll_view.Painting.Graphics = msg.Graphics
-								painter_view.Painting.Graphics = msg.Graphics
+								merged := msg.Graphics
+								if len(active_painters) > 1 {
+									painter_graphics[pmsg.Player] = msg.Graphics
+									parts := make([]Graphics, 0, len(active_painters))
+									for _, painter := range active_painters {
+										parts = append(parts, painter_graphics[painter])
+									}
+									merged = mergeGraphics(parts, msg.Graphics)
+								}
+								troll_view.Painting.Graphics = merged
+								painter_view.Painting.Graphics = merged
 
-								// Forward painting actions when the user changes the image.
+								// Forward painting actions when the user changes the image. The
+								// other painters of the team only need the new strokes, they
+								// still have their own:
 								session.BroadcastExcept(&PaintingChangedEvent{
-									Graphics: msg.Graphics,
-								}, pmsg.Player)
+									Graphics: merged,
+								}, active_painters...)
+								for _, painter := range active_painters {
+									if painter != pmsg.Player {
+										painter.Send(&PaintingChangedEvent{
+											Graphics: msg.Graphics,
+										})
+									}
+								}
 
 							} else {
 								session.ServerPrint("someone else tried to paint. BAD BOY!")
 							}
+
+						case *GuessCommand:
+							if guessing {
+								session.handleGuess(guess_round, active_painters, *pmsg, msg, round_end_timer)
+							} else {
+								session.ServerPrint("player tried to guess outside of guessing mode. BAD BOY!")
+							}
 						}
 					}
 
@@ -706,13 +1249,15 @@ func (session *Session) Run() {
 					TEXT_POPUP_START_STICKERING,
 				)
 
+				// Everyone may know the prompt now:
+				troll_view.Painting.Prompt = selected_painting_prompt
+				troll_view.CanGuess = false
+				session.setChatRules(chatRules{})
+
 				updateViews()
 
 				// Disable all active effects
-				session.Broadcast(&ChangeToolModifierEvent{
-					Modifier: "",
-					Duration: 0,
-				})
+				session.clearEffects()
 
 				// Phase 3:
 				session.DebugPrint(round_id, "Trolls now select stickers")
@@ -721,31 +1266,54 @@ func (session *Session) Run() {
 					painter_view.View = GAME_VIEW_ARTSTUDIO_GENERIC
 					painter_view.RemoveVote()
 
-					active_painter.Send(painter_view)
+					for _, painter := range active_painters {
+						painter.Send(painter_view)
+					}
 
-					// Manually initialize all trolls, as each troll has their own
-					// prompt items.
-					troll_view.View = GAME_VIEW_ARTSTUDIO_STICKER
+					// Only some trolls may sticker, so the painting stays recognizable
+					// in large sessions. It's the turn of those that stickered least:
+					stickerers := make([]*Player, 0, len(players))
 					for _, player := range players {
 						if player_role[player] == ROLE_TROLL {
-							troll_view.SetVote(
-								TEXT_VOTE_STICKERING,
-								nElementsFrom(random_source, ALL_STICKER_TAGS, 5),
-							)
-							player.Send(troll_view)
+							stickerers = append(stickerers, player)
 						}
 					}
+					stickerers = pickFairly(random_source, stickerers, sticker_turns, LIMIT_STICKERS_PER_PAINTING)
+
+					sticker_round := createStickerRound(session.Settings.Stickers)
+
+					// Shows the troll the painting with their own stickers. They
+					// keep their sticker options until all stickers are placed.
+					sendStickerView := func(player *Player) {
+						view := *painter_view
+						view.Painting.Stickers = sticker_round.placed[player]
+						if !sticker_round.done(player) {
+							view.View = GAME_VIEW_ARTSTUDIO_STICKER
+							view.SetVote(TEXT_VOTE_STICKERING, sticker_round.offered[player])
+						}
+						view.CanUndoSticker = len(sticker_round.placed[player]) > 0
+						player.Send(&view)
+					}
 
-					// Now that all trolls have their own stickers,
-					// we can now remove the vote again.
-					troll_view.RemoveVote()
-
-					mapped_stickers := make(map[*Player]*Sticker)
+					// Manually initialize all trolls, as each troll has their own
+					// prompt items.
+					for _, player := range players {
+						if containsPlayer(stickerers, player) {
+							sticker_round.offered[player] = nElementsFrom(random_source, session.stickerPool(), LIMIT_STICKER_OPTIONS)
+							sendStickerView(player)
+						} else if player_role[player] == ROLE_TROLL {
+							player.Send(painter_view)
+							player.Send(&PopUpEvent{
+								Message:  TEXT_POPUP_NO_STICKER,
+								Duration: TIME_POPUP_DURATION_MS,
+							})
+						}
+					}
 
 					round_end_timer := session.createTimer(TIME_GAME_STICKERING_S)
-					players_ready := createPlayerSetFromList(players, active_painter)
+					players_ready := createPlayerSetFromList(stickerers)
 
-					for !round_end_timer.TimedOut() && !players_ready.allTrollsSet() {
+					for !round_end_timer.TimedOut() && !players_ready.allSet() {
 						pmsg := session.PumpEvents(round_end_timer)
 						if pmsg == nil {
 							return
@@ -753,28 +1321,24 @@ func (session *Session) Run() {
 
 						switch msg := pmsg.Message.(type) {
 						case *PlaceStickerCommand:
-							if pmsg.Player != active_painter {
-								sticker := Sticker{
-									Id: msg.Sticker,
-									X:  msg.X,
-									Y:  msg.Y,
-								}
-								mapped_stickers[pmsg.Player] = &sticker
-
-								// hide the stickering options for the troll, but
-								// show them their own sticker:
-								{
-									personal_stickered_view := *painter_view
-									personal_stickered_view.Painting.Stickers = []Sticker{
-										sticker,
-									}
-									pmsg.Player.Send(&personal_stickered_view)
-								}
+							if reason := sticker_round.place(pmsg.Player, msg); reason != "" {
+								session.ServerPrint("Player ", pmsg.Player.NickName, " ", reason, ". BAD BOY!")
+								break
+							}
 
+							sendStickerView(pmsg.Player)
+							if sticker_round.done(pmsg.Player) {
 								players_ready.add(pmsg.Player)
-							} else {
-								session.ServerPrint("painted tried to sticker. BAD BOY!")
 							}
+
+						case *UndoStickerCommand:
+							if !sticker_round.undo(pmsg.Player) {
+								session.ServerPrint("Player ", pmsg.Player.NickName, " has no sticker to undo. BAD BOY!")
+								break
+							}
+
+							sendStickerView(pmsg.Player)
+							players_ready.remove(pmsg.Player)
 						}
 					}
 
@@ -787,25 +1351,19 @@ func (session *Session) Run() {
 						})
 					}
 
-					// fetch and put all placed stickers:
-					{
-						sticker_list := make([]Sticker, 0)
-						for _, sticker := range mapped_stickers {
-							if sticker != nil {
-								sticker_list = append(sticker_list, *sticker)
-							}
-						}
-						log.Println("stickers: ", sticker_list)
-						painter_view.Painting.Stickers = sticker_list
-					}
+					// put all placed stickers on the painting:
+					painter_view.Painting.Stickers = sticker_round.stickers()
+					log.Println("stickers: ", painter_view.Painting.Stickers)
 				}
 
 				// Store the result of that round
 				troll_view.Painting = painter_view.Painting
-				results[index] = gameRoundResult{
-					painting:    painter_view.Painting,
-					totalPoints: 0,
-				}
+				results = append(results, gameRoundResult{
+					painting: painter_view.Painting,
+					painters: active_painters,
+					ratings:  make(map[*Player]int),
+				})
+				result := &results[len(results)-1]
 
 				splitPopUp(
 					"",
@@ -816,13 +1374,18 @@ func (session *Session) Run() {
 
 				// Phase 4:
 				session.DebugPrint(round_id, "Showcase the artwork")
+				session.setChatRules(chatRules{
+					reactions: true,
+				})
 				{
 					round_end_timer := session.createTimer(TIME_GAME_SHOWCASE_S)
-					players_ready := createPlayerSetFromMap(session.Players, nil)
+					players_ready := createPlayerSetFromList(session.Players)
+					sticker_voted := make(map[*Player]bool)
 
 					changeBoth(func(view *ChangeGameViewEvent) {
 						view.View = GAME_VIEW_ARTSTUDIO_GENERIC
 						view.SetVote(TEXT_VOTE_SHOWCASE, []string{"", "", "", "", "continue"})
+						view.CanReact = true
 					})
 
 					updateViews()
@@ -846,6 +1409,21 @@ func (session *Session) Run() {
 								players_ready.add(pmsg.Player)
 								pmsg.Player.Send(troll_view) // it doesn't matter, they should be equal
 							}
+
+						case *VoteStickerCommand:
+							stickers := result.painting.Stickers
+							if msg.Sticker < 0 || msg.Sticker >= len(stickers) {
+								session.ServerPrint("User voted for a sticker that doesn't exist, BAD BOY")
+							} else if stickers[msg.Sticker].Author == pmsg.Player.Id {
+								session.ServerPrint("User voted for their own sticker, BAD BOY")
+							} else if sticker_voted[pmsg.Player] {
+								session.ServerPrint("User voted for two stickers, BAD BOY")
+							} else {
+								sticker_voted[pmsg.Player] = true
+								if author := session.findMatchPlayer(stickers[msg.Sticker].Author); author != nil {
+									session.scoreCard(author).stickers += POINTS_STICKER_VOTE
+								}
+							}
 						}
 					}
 					round_end_timer.Hide()
@@ -862,14 +1440,20 @@ func (session *Session) Run() {
 			} // end of inner loop over players
 
 			// Phase 5:
+			session.setChatRules(chatRules{
+				reactions: true,
+			})
 			{
-				// TODO: Loop through all results and let the players vote for the pictures
+				// Large matches would take forever, so only some paintings are rated:
+				rated := selectRatedResults(random_source, results, LIMIT_RATED_PAINTINGS)
 
-				for index, result := range results {
-					round_id := fmt.Sprintf("Showcase %d: ", index+1)
+				for showcase, index := range rated {
+					results[index].rated = true
+					result := results[index]
+					round_id := fmt.Sprintf("Showcase %d: ", showcase+1)
 
 					fmt_context := AnnouncementContext{
-						PainterName: players[index].NickName,
+						PainterName: teamName(result.painters),
 					}
 
 					session.Announce(TEXT_ANNOUNCE_VOTE_NOW.Format(fmt_context), TIME_ANNOUNCE_GENERIC)
@@ -879,7 +1463,14 @@ func (session *Session) Run() {
 					vote_view := ChangeGameViewEvent{
 						View:     GAME_VIEW_ARTSTUDIO_GENERIC,
 						Painting: result.painting,
+						CanReact: true,
 					}
+
+					// The painters don't rate their own painting:
+					for _, painter := range result.painters {
+						painter.Send(&vote_view)
+					}
+
 					vote_view.SetVote(TEXT_VOTE_SHOWCASE, []string{
 						"star1",
 						"star2",
@@ -887,42 +1478,43 @@ func (session *Session) Run() {
 						"star4",
 						"star5",
 					})
-					session.Broadcast(&vote_view)
+					session.BroadcastExcept(&vote_view, result.painters...)
 
 					// Hide the vote for later sending:
 					vote_view.RemoveVote()
 
 					round_end_timer := session.createTimer(TIME_GAME_RATING_S)
-					players_ready := createPlayerSetFromMap(session.Players, nil)
-					for !round_end_timer.TimedOut() && !players_ready.allSet() {
+					players_ready := createPlayerSetFromList(session.Players, result.painters...)
+					voters := len(session.Players) - len(session.presentMembers(result.painters))
+					reported_votes := 0
+					for !round_end_timer.TimedOut() && !players_ready.allTrollsSet() {
 						pmsg := session.PumpEvents(round_end_timer)
 						if pmsg == nil {
 							return
 						}
 
 						switch msg := pmsg.Message.(type) {
+						case *NotifyTimeout:
+							// The progress is sent once a second instead of for every vote,
+							// which would flood large sessions:
+							if len(result.ratings) != reported_votes {
+								reported_votes = len(result.ratings)
+								session.Broadcast(&RatingProgressEvent{
+									Votes:  reported_votes,
+									Voters: voters,
+								})
+							}
+
 						case *VoteCommand:
 
-							if !players_ready.isSet(pmsg.Player) {
-
-								ok := true
-								switch msg.Option {
-								case "star1":
-									results[index].totalPoints += 1
-								case "star2":
-									results[index].totalPoints += 2
-								case "star3":
-									results[index].totalPoints += 3
-								case "star4":
-									results[index].totalPoints += 4
-								case "star5":
-									results[index].totalPoints += 5
-
-								default:
-									ok = false
-								}
+							if containsPlayer(result.painters, pmsg.Player) {
+								session.ServerPrint("painter tried to rate their own painting. BAD BOY!")
+
+							} else if !players_ready.isSet(pmsg.Player) {
 
+								stars, ok := RATING_OPTIONS[msg.Option]
 								if ok {
+									results[index].ratings[pmsg.Player] = stars
 									players_ready.add(pmsg.Player)
 									pmsg.Player.Send(&vote_view)
 								}
@@ -951,39 +1543,71 @@ func (session *Session) Run() {
 
 			// Determine winner:
 			{
-				best_painting_score := 0
-				best_painting_index := 0
-
 				for i := range results {
-					results[i].painting.Winner = false
+					results[i].painting.Score = results[i].averageRating()
+					results[i].painting.Votes = len(results[i].ratings)
+				}
 
-					if results[i].totalPoints >= best_painting_score {
-						best_painting_score = results[i].totalPoints
-						best_painting_index = i
+				best_painting_score := float32(0)
+				for i := range results {
+					if results[i].painting.Votes > 0 && results[i].painting.Score > best_painting_score {
+						best_painting_score = results[i].painting.Score
 					}
 				}
 
-				results[best_painting_index].painting.Winner = true
+				// All paintings with the best score win, paintings nobody rated can't win:
+				for i := range results {
+					results[i].painting.Winner = results[i].painting.Votes > 0 &&
+						results[i].painting.Score == best_painting_score
+				}
+
+				// Painters are credited with the average rating of their rated
+				// paintings, so painting more often doesn't earn more points:
+				painting_counts := make(map[*Player]int)
+				for i := range results {
+					if !results[i].rated {
+						continue
+					}
+					for _, painter := range results[i].painters {
+						painting_counts[painter] += 1
+					}
+				}
+				for i := range results {
+					if !results[i].rated {
+						continue
+					}
+					for _, painter := range results[i].painters {
+						session.scoreCard(painter).painting += results[i].painting.Score / float32(painting_counts[painter])
+					}
+				}
+				session.BroadcastPlayers(nil, nil)
 			}
 
 			// Phase 6:
 			session.DebugPrint("Showcase the winner")
 			{
 				view_cmd := ChangeGameViewEvent{
-					View:    GAME_VIEW_GALLERY,
-					Results: make([]Painting, len(results)),
+					View:     GAME_VIEW_GALLERY,
+					Results:  make([]Painting, len(results)),
+					CanReact: true,
 				}
 
 				for i := range view_cmd.Results {
 					view_cmd.Results[i] = results[i].painting
-					view_cmd.Results[i].Score = results[i].totalPoints
 				}
 
 				// TODO set drawing of winner
 				session.Broadcast(&view_cmd)
+				leaderboard := session.leaderboard()
+				team_leaderboard := session.teamLeaderboard()
+				session.Broadcast(&LeaderboardEvent{
+					Entries: leaderboard,
+					Teams:   team_leaderboard,
+				})
+				session.recordMatch(leaderboard, team_leaderboard, results)
 
 				round_end_timer := session.createTimer(TIME_GAME_GALLERY_S)
-				players_ready := createPlayerSetFromMap(session.Players, nil)
+				players_ready := createPlayerSetFromList(session.Players)
 				for !round_end_timer.TimedOut() && !players_ready.allSet() {
 					pmsg := session.PumpEvents(round_end_timer)
 					if pmsg == nil {
@@ -1022,27 +1646,7 @@ type playerSet struct {
 	items map[*Player]*playerSetItem
 }
 
-func createPlayerSetFromMap(players map[*Player]bool, painter *Player) playerSet {
-
-	items := make(map[*Player]*playerSetItem)
-
-	for p := range players {
-		item := playerSetItem{
-			value: false,
-			role:  ROLE_TROLL,
-		}
-		if p == painter {
-			item.role = ROLE_PAINTER
-		}
-		items[p] = &item
-	}
-
-	return playerSet{
-		items: items,
-	}
-}
-
-func createPlayerSetFromList(players []*Player, painter *Player) playerSet {
+func createPlayerSetFromList(players []*Player, painters ...*Player) playerSet {
 
 	items := make(map[*Player]*playerSetItem)
 
@@ -1051,7 +1655,7 @@ func createPlayerSetFromList(players []*Player, painter *Player) playerSet {
 			value: false,
 			role:  ROLE_TROLL,
 		}
-		if p == painter {
+		if containsPlayer(painters, p) {
 			item.role = ROLE_PAINTER
 		}
 		items[p] = &item
@@ -1096,10 +1700,11 @@ func (set *playerSet) allTrollsSet() bool {
 	return true
 }
 
+// Checks if any of the painters is set.
 func (set *playerSet) painterSet() bool {
 	for _, item := range set.items {
-		if item.role == ROLE_PAINTER {
-			return item.value
+		if item.role == ROLE_PAINTER && item.value {
+			return true
 		}
 	}
 	return false
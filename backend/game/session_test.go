@@ -0,0 +1,146 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+// Plays a client of the session from within a test.
+type testClient struct {
+	t       *testing.T
+	player  *Player
+	pending []Message
+}
+
+func createTestClient(t *testing.T) *testClient {
+	return &testClient{
+		t:      t,
+		player: CreatePlayer(&botTransport{done: make(chan struct{})}),
+	}
+}
+
+func (client *testClient) send(msg Message) {
+	client.t.Helper()
+	data, err := SerializeMessage(msg)
+	if err != nil {
+		client.t.Fatal(err)
+	}
+	if err := client.player.HandleInbound(data); err != nil {
+		client.t.Fatal(err)
+	}
+}
+
+// Returns the next message the client receives that `match` accepts.
+// Skipped messages are passed to `skip`, if given.
+func (client *testClient) waitFor(what string, match func(msg Message) bool, skip func(msg Message)) Message {
+	client.t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		for len(client.pending) > 0 {
+			msg := client.pending[0]
+			client.pending = client.pending[1:]
+			if match(msg) {
+				return msg
+			}
+			if skip != nil {
+				skip(msg)
+			}
+		}
+
+		select {
+		case <-client.player.OutboundSignal():
+			messages, _ := client.player.TakeOutbound()
+			for _, data := range messages {
+				msg, err := DeserializeMessage(data)
+				if err != nil {
+					client.t.Fatal(err)
+				}
+				client.pending = append(client.pending, msg)
+			}
+		case <-deadline:
+			client.t.Fatalf("%s didn't receive %s", client.player.NickName, what)
+		}
+	}
+}
+
+func isPromptVoteView(msg Message) bool {
+	view, ok := msg.(*ChangeGameViewEvent)
+	return ok && (view.View == GAME_VIEW_PROMPTSELECTION || view.View == GAME_VIEW_ARTSTUDIO_GENERIC)
+}
+
+func TestVoteBackdropThenPrompt(t *testing.T) {
+	announce := TIME_ANNOUNCE_GENERIC
+	TIME_ANNOUNCE_GENERIC = time.Millisecond
+	defer func() { TIME_ANNOUNCE_GENERIC = announce }()
+
+	host := createTestClient(t)
+	guest := createTestClient(t)
+	defer host.player.Close()
+	defer guest.player.Close()
+
+	host.send(&CreateSessionCommand{NickName: "Host"})
+	enter := host.waitFor("the session", func(msg Message) bool {
+		_, ok := msg.(*EnterSessionEvent)
+		return ok
+	}, nil).(*EnterSessionEvent)
+
+	guest.send(&JoinSessionCommand{NickName: "Guest", SessionId: enter.SessionId})
+	guest.waitFor("the session", func(msg Message) bool {
+		_, ok := msg.(*EnterSessionEvent)
+		return ok
+	}, nil)
+
+	settings := defaultSessionSettings()
+	settings.BackdropVote = true
+	host.send(&ChangeSettingsCommand{Settings: settings})
+	host.waitFor("the settings", func(msg Message) bool {
+		changed, ok := msg.(*SettingsChangedEvent)
+		return ok && changed.Settings.BackdropVote
+	}, nil)
+
+	host.send(&UserCommand{Action: USER_ACTION_SET_READY})
+	guest.send(&UserCommand{Action: USER_ACTION_SET_READY})
+
+	host_view := host.waitFor("the prompt vote", isPromptVoteView, nil).(*ChangeGameViewEvent)
+	guest_view := guest.waitFor("the prompt vote", isPromptVoteView, nil).(*ChangeGameViewEvent)
+
+	troll, troll_view, painter := host, host_view, guest
+	if guest_view.View == GAME_VIEW_PROMPTSELECTION {
+		troll, troll_view, painter = guest, guest_view, host
+	}
+	if troll_view.View != GAME_VIEW_PROMPTSELECTION || len(troll_view.VoteOptions) == 0 {
+		t.Fatalf("troll can't vote for the prompt: %+v", troll_view)
+	}
+	if len(troll_view.BackdropOptions) != LIMIT_BACKDROP_OPTIONS {
+		t.Fatalf("troll got backdrop options %v", troll_view.BackdropOptions)
+	}
+
+	backdrop := troll_view.BackdropOptions[1]
+	prompt := troll_view.VoteOptions[0]
+
+	// The chat message is handled after the backdrop vote, so everything
+	// sent because of the vote arrives before it:
+	troll.send(&VoteBackdropCommand{Backdrop: backdrop})
+	troll.send(&ChatCommand{Message: "hello"})
+	troll.waitFor("the chat message", func(msg Message) bool {
+		_, ok := msg.(*ChatMessageEvent)
+		return ok
+	}, func(msg Message) {
+		if view, ok := msg.(*ChangeGameViewEvent); ok && len(view.VoteOptions) == 0 {
+			t.Fatalf("troll lost the prompt vote after voting for the backdrop: %+v", view)
+		}
+	})
+
+	troll.send(&VoteCommand{Option: prompt})
+	painter_view := painter.waitFor("the painting view", func(msg Message) bool {
+		view, ok := msg.(*ChangeGameViewEvent)
+		return ok && view.View == GAME_VIEW_ARTSTUDIO_ACTIVE
+	}, nil).(*ChangeGameViewEvent)
+
+	if painter_view.Painting.Prompt != prompt {
+		t.Errorf("painter got prompt %q, want %q", painter_view.Painting.Prompt, prompt)
+	}
+	if painter_view.Painting.Backdrop != backdrop {
+		t.Errorf("painter got backdrop %q, want %q", painter_view.Painting.Backdrop, backdrop)
+	}
+}
@@ -0,0 +1,118 @@
+package game
+
+// Checks the settings requested by the host. Returns the reason if they
+// can't be used.
+func (session *Session) checkSettings(settings SessionSettings) string {
+	if settings.MaxPlayers < 2 || settings.MaxPlayers > LIMIT_MAX_PLAYERS {
+		return TEXT_ERROR_BAD_MAX_PLAYERS
+	}
+	if settings.MaxPlayers < len(session.Players) {
+		return TEXT_ERROR_TOO_MANY_PLAYERS
+	}
+
+	if settings.PaintingTime < LIMIT_MIN_PAINTING_TIME_S || settings.PaintingTime > LIMIT_MAX_PAINTING_TIME_S {
+		return TEXT_ERROR_BAD_PAINTING_TIME
+	}
+
+	valid_mode := false
+	for _, mode := range ALL_GAME_MODE_ITEMS {
+		if settings.Mode == mode {
+			valid_mode = true
+		}
+	}
+	if !valid_mode {
+		return TEXT_ERROR_BAD_GAME_MODE
+	}
+
+	valid_order := false
+	for _, order := range ALL_TURN_ORDER_ITEMS {
+		if settings.TurnOrder == order {
+			valid_order = true
+		}
+	}
+	if !valid_order {
+		return TEXT_ERROR_BAD_TURN_ORDER
+	}
+	if settings.PaintTurns < 1 || settings.PaintTurns > LIMIT_MAX_PAINT_TURNS {
+		return TEXT_ERROR_BAD_PAINT_TURNS
+	}
+	if settings.Rounds < 1 || settings.Rounds > LIMIT_MAX_ROUNDS {
+		return TEXT_ERROR_BAD_ROUNDS
+	}
+	if settings.Stickers < 1 || settings.Stickers > LIMIT_MAX_STICKERS_PER_TROLL {
+		return TEXT_ERROR_BAD_STICKERS
+	}
+
+	if len(settings.PromptPacks) == 0 {
+		return TEXT_ERROR_BAD_PROMPT_PACKS
+	}
+	selected_packs := make(map[string]bool)
+	for _, id := range settings.PromptPacks {
+		if _, ok := promptPacks[id]; !ok || selected_packs[id] {
+			return TEXT_ERROR_BAD_PROMPT_PACKS
+		}
+		selected_packs[id] = true
+	}
+
+	if len(settings.StickerPacks) == 0 {
+		return TEXT_ERROR_BAD_STICKER_PACKS
+	}
+	selected_sticker_packs := make(map[string]bool)
+	for _, id := range settings.StickerPacks {
+		if _, ok := stickerPacks[id]; !ok || selected_sticker_packs[id] {
+			return TEXT_ERROR_BAD_STICKER_PACKS
+		}
+		selected_sticker_packs[id] = true
+	}
+
+	if len(settings.BackdropPacks) == 0 {
+		return TEXT_ERROR_BAD_BACKDROP_PACKS
+	}
+	selected_backdrop_packs := make(map[string]bool)
+	for _, id := range settings.BackdropPacks {
+		if _, ok := backdropPacks[id]; !ok || selected_backdrop_packs[id] {
+			return TEXT_ERROR_BAD_BACKDROP_PACKS
+		}
+		selected_backdrop_packs[id] = true
+	}
+
+	if len(settings.Effects) == 0 {
+		return TEXT_ERROR_BAD_EFFECTS
+	}
+	selected_effects := make(map[Effect]bool)
+	for _, id := range settings.Effects {
+		if _, ok := TROLL_EFFECTS[id]; !ok || selected_effects[id] {
+			return TEXT_ERROR_BAD_EFFECTS
+		}
+		selected_effects[id] = true
+	}
+
+	return ""
+}
+
+// Lets the host change the settings in the lobby. Everyone has to agree to
+// the new settings, so all players are set to not ready.
+func (session *Session) handleChangeSettings(pmsg PlayerMessage, msg *ChangeSettingsCommand) {
+	if pmsg.Player != session.HostPlayer {
+		session.ServerPrint("Player ", pmsg.Player.NickName, " tried to change the settings. BAD BOY!")
+		return
+	}
+
+	reason := session.checkSettings(msg.Settings)
+	if reason != "" {
+		pmsg.Player.Send(&PopUpEvent{
+			Message:  reason,
+			Duration: TIME_POPUP_DURATION_MS,
+		})
+		return
+	}
+
+	session.ServerPrint("Settings changed to ", msg.Settings)
+	session.Settings = msg.Settings
+	session.readyPlayers = make(map[*Player]bool)
+
+	session.Broadcast(&SettingsChangedEvent{
+		Settings: session.Settings,
+	})
+	session.publishSnapshot()
+}
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"log"
 	"time"
 
 	"random-projects.net/crayos-backend/meta"
@@ -17,4 +18,33 @@ func Setup() {
 		TIME_GAME_GALLERY_S = 20
 		TIME_ANNOUNCE_GENERIC = 500 * time.Millisecond
 	}
+
+	if *meta.PROMPT_PACKS_DIR != "" {
+		err := loadPromptPacks(*meta.PROMPT_PACKS_DIR)
+		if err != nil {
+			log.Fatalln("failed to load prompt packs: ", err)
+		}
+	}
+
+	if *meta.BACKDROP_PACKS_DIR != "" {
+		err := loadBackdropPacks(*meta.BACKDROP_PACKS_DIR)
+		if err != nil {
+			log.Fatalln("failed to load backdrop packs: ", err)
+		}
+	}
+
+	if *meta.STICKER_PACKS_DIR != "" {
+		err := loadStickerPacks(*meta.STICKER_PACKS_DIR)
+		if err != nil {
+			log.Fatalln("failed to load sticker packs: ", err)
+		}
+	}
+
+	if *meta.CHAT_BLOCKLIST_FILE != "" {
+		err := loadChatBlocklist(*meta.CHAT_BLOCKLIST_FILE)
+		if err != nil {
+			log.Fatalln("failed to load chat blocklist: ", err)
+		}
+		log.Println("Loaded", len(chatBlockedWords), "blocked chat words")
+	}
 }
@@ -0,0 +1,103 @@
+package game
+
+import (
+	"math"
+	"sort"
+)
+
+// State of the stickering on the current painting.
+type stickerRound struct {
+	limit   int                  // stickers each troll may place
+	offered map[*Player][]string // stickers each troll may choose from
+	placed  map[*Player][]Sticker
+	next_z  int // stickers placed later are drawn on top
+}
+
+func createStickerRound(limit int) *stickerRound {
+	return &stickerRound{
+		limit:   limit,
+		offered: make(map[*Player][]string),
+		placed:  make(map[*Player][]Sticker),
+	}
+}
+
+// Checks if the troll has used up all their stickers.
+func (round *stickerRound) done(player *Player) bool {
+	return len(round.placed[player]) >= round.limit
+}
+
+// Places a sticker for the troll. Returns why the sticker can't be placed,
+// or an empty string if it was placed.
+func (round *stickerRound) place(player *Player, msg *PlaceStickerCommand) string {
+	options, ok := round.offered[player]
+	if !ok {
+		return "may not sticker this painting"
+	}
+	if round.done(player) {
+		return "has no stickers left"
+	}
+
+	if _, ok := stickerIndex[msg.Sticker]; !ok {
+		return "sent an unknown sticker"
+	}
+	offered := false
+	for _, option := range options {
+		if option == msg.Sticker {
+			offered = true
+		}
+	}
+	if !offered {
+		return "sent a sticker that wasn't offered"
+	}
+
+	// Written so that NaN is rejected, too:
+	if !(msg.X >= 0 && msg.X <= float32(LIMIT_CANVAS_WIDTH) && msg.Y >= 0 && msg.Y <= float32(LIMIT_CANVAS_HEIGHT)) {
+		return "placed a sticker outside of the canvas"
+	}
+	if !(msg.Scale >= LIMIT_MIN_STICKER_SCALE && msg.Scale <= LIMIT_MAX_STICKER_SCALE) {
+		return "sent an invalid sticker scale"
+	}
+	rotation := math.Mod(float64(msg.Rotation), 360)
+	if math.IsNaN(rotation) {
+		return "sent an invalid sticker rotation"
+	}
+	if rotation < 0 {
+		rotation += 360
+	}
+
+	round.placed[player] = append(round.placed[player], Sticker{
+		Id:       msg.Sticker,
+		X:        msg.X,
+		Y:        msg.Y,
+		Author:   player.Id,
+		Scale:    msg.Scale,
+		Rotation: float32(rotation),
+		Flip:     msg.Flip,
+		Z:        round.next_z,
+	})
+	round.next_z += 1
+
+	return ""
+}
+
+// Removes the last sticker the troll placed. Returns false if there is none.
+func (round *stickerRound) undo(player *Player) bool {
+	placed := round.placed[player]
+	if len(placed) == 0 {
+		return false
+	}
+	round.placed[player] = placed[:len(placed)-1]
+	return true
+}
+
+// Returns the stickers of all trolls, in the order they are drawn.
+func (round *stickerRound) stickers() []Sticker {
+	stickers := make([]Sticker, 0)
+	for _, placed := range round.placed {
+		stickers = append(stickers, placed...)
+	}
+	sort.Slice(stickers, func(i, j int) bool {
+		return stickers[i].Z < stickers[j].Z
+	})
+	return stickers
+}
@@ -0,0 +1,261 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Id of the sticker pack that is built into the frontend.
+const DEFAULT_STICKER_PACK = "default"
+
+// Size of the built-in stickers, the images are a bit too large.
+const defaultStickerScale float32 = 0.7
+
+// Additional sticker packs are served below this path.
+const STICKER_PACKS_URL = "/sticker-packs/"
+
+// Image types stickers and backdrops may have.
+var packImageExtensions = map[string]bool{
+	".png":  true,
+	".webp": true,
+	".gif":  true,
+	".svg":  true,
+}
+
+// A set of stickers with some metadata. Additional packs are loaded from the
+// -sticker-packs directory, one subdirectory per pack with the images and
+// a pack.json:
+//
+//	{
+//	  "name": "Outer Space",
+//	  "category": "sci-fi",
+//	  "scale": 0.5,
+//	  "stickers": [
+//	    { "file": "rocket.png", "name": "Rocket", "scale": 0.8 },
+//	    ...
+//	  ]
+//	}
+//
+// The id of the pack is the name of the directory. The scale of a sticker
+// is optional and defaults to the scale of the pack, which defaults to 1.
+type StickerPack struct {
+	Id       string             `json:"-"`
+	Name     string             `json:"name"`
+	Category string             `json:"category"`
+	Scale    float32            `json:"scale"`
+	Stickers []stickerPackEntry `json:"stickers"`
+
+	dir   string        // empty for the built-in pack
+	infos []StickerInfo // what the frontend needs to know about the stickers
+}
+
+type stickerPackEntry struct {
+	File  string  `json:"file"`
+	Name  string  `json:"name"`
+	Scale float32 `json:"scale"`
+}
+
+// All available sticker packs by id.
+var stickerPacks = map[string]*StickerPack{
+	DEFAULT_STICKER_PACK: createDefaultStickerPack(),
+}
+
+// All stickers of all packs by their id.
+var stickerIndex = indexStickers(stickerPacks[DEFAULT_STICKER_PACK])
+
+// Turns a file name like "rubber-duck" into "Rubber duck".
+func packItemName(file string) string {
+	name := strings.NewReplacer("-", " ", "_", " ").Replace(file)
+	first, size := utf8.DecodeRuneInString(name)
+	if size == 0 {
+		return name
+	}
+	return string(unicode.ToUpper(first)) + name[size:]
+}
+
+func createDefaultStickerPack() *StickerPack {
+	pack := &StickerPack{
+		Id:       DEFAULT_STICKER_PACK,
+		Name:     "Crayos",
+		Category: "mixed",
+		Scale:    defaultStickerScale,
+	}
+	for _, tag := range ALL_STICKER_TAGS {
+		pack.infos = append(pack.infos, StickerInfo{
+			Id:    tag,
+			Name:  packItemName(tag),
+			Url:   "img/stickers/" + tag + ".png",
+			Scale: defaultStickerScale,
+		})
+	}
+	return pack
+}
+
+func indexStickers(pack *StickerPack) map[string]*StickerInfo {
+	index := make(map[string]*StickerInfo)
+	for i := range pack.infos {
+		index[pack.infos[i].Id] = &pack.infos[i]
+	}
+	return index
+}
+
+// Checks that the image of a pack exists. Only plain file names with a
+// name before the extension are allowed, so nothing outside of the pack is
+// served.
+func checkPackImage(dir string, file string) error {
+	ext := filepath.Ext(file)
+	if file == ext || file != filepath.Base(file) || !packImageExtensions[strings.ToLower(ext)] {
+		return fmt.Errorf("invalid image file %q", file)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, file))
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a file", file)
+	}
+	return nil
+}
+
+func loadStickerPack(dir string) (*StickerPack, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "pack.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	pack := &StickerPack{
+		Id:  filepath.Base(dir),
+		dir: dir,
+	}
+	err = json.Unmarshal(data, pack)
+	if err != nil {
+		return nil, err
+	}
+
+	if pack.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if pack.Scale == 0 {
+		pack.Scale = 1
+	}
+	if pack.Scale < 0 {
+		return nil, fmt.Errorf("invalid scale %v", pack.Scale)
+	}
+
+	seen := make(map[string]bool)
+	for _, sticker := range pack.Stickers {
+		if err := checkPackImage(dir, sticker.File); err != nil {
+			return nil, err
+		}
+		if seen[sticker.File] {
+			return nil, fmt.Errorf("duplicate sticker file %q", sticker.File)
+		}
+		seen[sticker.File] = true
+
+		scale := sticker.Scale
+		if scale == 0 {
+			scale = pack.Scale
+		}
+		if scale < 0 {
+			return nil, fmt.Errorf("invalid scale %v of %q", scale, sticker.File)
+		}
+
+		name := sticker.Name
+		if name == "" {
+			name = packItemName(strings.TrimSuffix(sticker.File, filepath.Ext(sticker.File)))
+		}
+
+		pack.infos = append(pack.infos, StickerInfo{
+			Id:    pack.Id + "/" + sticker.File,
+			Name:  name,
+			Url:   path.Join(STICKER_PACKS_URL, pack.Id, sticker.File),
+			Scale: scale,
+		})
+	}
+
+	if len(pack.infos) < LIMIT_STICKER_OPTIONS {
+		return nil, fmt.Errorf("at least %d stickers are required", LIMIT_STICKER_OPTIONS)
+	}
+
+	return pack, nil
+}
+
+// Loads all sticker packs from the subdirectories of the directory.
+func loadStickerPacks(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		pack_dir := filepath.Join(dir, entry.Name())
+		pack, err := loadStickerPack(pack_dir)
+		if err != nil {
+			return fmt.Errorf("%s: %w", pack_dir, err)
+		}
+		if _, ok := stickerPacks[pack.Id]; ok {
+			return fmt.Errorf("%s: duplicate sticker pack id %q", pack_dir, pack.Id)
+		}
+
+		stickerPacks[pack.Id] = pack
+		for id, info := range indexStickers(pack) {
+			stickerIndex[id] = info
+		}
+		log.Println("Loaded sticker pack", pack.Id, "with", len(pack.infos), "stickers")
+	}
+	return nil
+}
+
+// Lists the available sticker packs sorted by id.
+func listStickerPacks() []StickerPackInfo {
+	infos := make([]StickerPackInfo, 0, len(stickerPacks))
+	for _, pack := range stickerPacks {
+		infos = append(infos, StickerPackInfo{
+			Id:       pack.Id,
+			Name:     pack.Name,
+			Category: pack.Category,
+			Stickers: pack.infos,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Id < infos[j].Id
+	})
+	return infos
+}
+
+// Returns the file of a sticker of a loaded pack, so it can be served.
+// The built-in stickers are part of the frontend and aren't found here.
+func StickerFile(pack_id string, file string) (string, bool) {
+	pack, ok := stickerPacks[pack_id]
+	if !ok || pack.dir == "" {
+		return "", false
+	}
+	if _, ok := stickerIndex[pack_id+"/"+file]; !ok {
+		return "", false
+	}
+	return filepath.Join(pack.dir, file), true
+}
+
+// Collects the ids of all stickers of the selected packs.
+func (session *Session) stickerPool() []string {
+	pool := make([]string, 0)
+	for _, id := range session.Settings.StickerPacks {
+		for _, info := range stickerPacks[id].infos {
+			pool = append(pool, info.Id)
+		}
+	}
+	return pool
+}
@@ -0,0 +1,54 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackItemName(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"rubber-duck", "Rubber duck"},
+		{"dirty_dishes", "Dirty dishes"},
+		{"äpfel", "Äpfel"},
+		{"баба", "Баба"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := packItemName(test.file); got != test.want {
+			t.Errorf("packItemName(%q) = %q, want %q", test.file, got, test.want)
+		}
+	}
+}
+
+func TestCheckPackImage(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"rocket.png", ".png"} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte{}, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		file  string
+		valid bool
+	}{
+		{"rocket.png", true},
+		{"missing.png", false},
+		{".png", false},
+		{"", false},
+		{"rocket.txt", false},
+		{"../rocket.png", false},
+	}
+
+	for _, test := range tests {
+		err := checkPackImage(dir, test.file)
+		if (err == nil) != test.valid {
+			t.Errorf("checkPackImage(%q) = %v, want valid: %v", test.file, err, test.valid)
+		}
+	}
+}
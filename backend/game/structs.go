@@ -9,19 +9,45 @@ const (
 	CREATE_SESSION_COMMAND_TAG = "create-session-command"
 	JOIN_SESSION_COMMAND_TAG = "join-session-command"
 	LEAVE_SESSION_COMMAND_TAG = "leave-session-command"
+	CREATE_INVITE_COMMAND_TAG = "create-invite-command"
+	LIST_PUBLIC_SESSIONS_COMMAND_TAG = "list-public-sessions-command"
+	QUICK_MATCH_COMMAND_TAG = "quick-match-command"
+	CHANGE_SETTINGS_COMMAND_TAG = "change-settings-command"
+	SUBMIT_PROMPT_COMMAND_TAG = "submit-prompt-command"
+	ADD_BOT_COMMAND_TAG = "add-bot-command"
+	REMOVE_BOT_COMMAND_TAG = "remove-bot-command"
+	RESET_SCOREBOARD_COMMAND_TAG = "reset-scoreboard-command"
 	USER_COMMAND_TAG = "user-command"
 	VOTE_COMMAND_TAG = "vote-command"
+	CHAT_COMMAND_TAG = "chat-command"
+	REACT_COMMAND_TAG = "react-command"
+	GUESS_COMMAND_TAG = "guess-command"
+	VOTE_STICKER_COMMAND_TAG = "vote-sticker-command"
 	PLACE_STICKER_COMMAND_TAG = "place-sticker-command"
+	VOTE_BACKDROP_COMMAND_TAG = "vote-backdrop-command"
+	UNDO_STICKER_COMMAND_TAG = "undo-sticker-command"
 	SET_PAINTING_COMMAND_TAG = "set-painting-command"
 	ENTER_SESSION_EVENT_TAG = "enter-session-event"
 	JOIN_SESSION_FAILED_EVENT_TAG = "join-session-failed-event"
 	KICKED_EVENT_TAG = "kicked-event"
+	SETTINGS_CHANGED_EVENT_TAG = "settings-changed-event"
+	PROMPT_PACKS_EVENT_TAG = "prompt-packs-event"
+	STICKER_PACKS_EVENT_TAG = "sticker-packs-event"
+	BACKDROP_PACKS_EVENT_TAG = "backdrop-packs-event"
+	SUBMITTED_PROMPTS_EVENT_TAG = "submitted-prompts-event"
+	INVITE_CREATED_EVENT_TAG = "invite-created-event"
+	PUBLIC_SESSIONS_EVENT_TAG = "public-sessions-event"
 	CHANGE_GAME_VIEW_EVENT_TAG = "change-game-view-event"
 	TIMER_CHANGED_EVENT_TAG = "timer-changed-event"
-	CHANGE_TOOL_MODIFIER_EVENT_TAG = "change-tool-modifier-event"
+	ACTIVE_EFFECTS_CHANGED_EVENT_TAG = "active-effects-changed-event"
 	PAINTING_CHANGED_EVENT_TAG = "painting-changed-event"
 	PLAYERS_CHANGED_EVENT_TAG = "players-changed-event"
 	PLAYER_READY_CHANGED_EVENT_TAG = "player-ready-changed-event"
+	CHAT_MESSAGE_EVENT_TAG = "chat-message-event"
+	REACTION_EVENT_TAG = "reaction-event"
+	RATING_PROGRESS_EVENT_TAG = "rating-progress-event"
+	LEADERBOARD_EVENT_TAG = "leaderboard-event"
+	SCOREBOARD_EVENT_TAG = "scoreboard-event"
 	POP_UP_EVENT_TAG = "pop-up-event"
 	DEBUG_MESSAGE_EVENT_TAG = "debug-message-event"
 )
@@ -50,12 +76,40 @@ func DeserializeMessage(data []byte) (Message, error) {
 		out = &JoinSessionCommand{}
 	case LEAVE_SESSION_COMMAND_TAG:
 		out = &LeaveSessionCommand{}
+	case CREATE_INVITE_COMMAND_TAG:
+		out = &CreateInviteCommand{}
+	case LIST_PUBLIC_SESSIONS_COMMAND_TAG:
+		out = &ListPublicSessionsCommand{}
+	case QUICK_MATCH_COMMAND_TAG:
+		out = &QuickMatchCommand{}
+	case CHANGE_SETTINGS_COMMAND_TAG:
+		out = &ChangeSettingsCommand{}
+	case SUBMIT_PROMPT_COMMAND_TAG:
+		out = &SubmitPromptCommand{}
+	case ADD_BOT_COMMAND_TAG:
+		out = &AddBotCommand{}
+	case REMOVE_BOT_COMMAND_TAG:
+		out = &RemoveBotCommand{}
+	case RESET_SCOREBOARD_COMMAND_TAG:
+		out = &ResetScoreboardCommand{}
 	case USER_COMMAND_TAG:
 		out = &UserCommand{}
 	case VOTE_COMMAND_TAG:
 		out = &VoteCommand{}
+	case CHAT_COMMAND_TAG:
+		out = &ChatCommand{}
+	case REACT_COMMAND_TAG:
+		out = &ReactCommand{}
+	case GUESS_COMMAND_TAG:
+		out = &GuessCommand{}
+	case VOTE_STICKER_COMMAND_TAG:
+		out = &VoteStickerCommand{}
 	case PLACE_STICKER_COMMAND_TAG:
 		out = &PlaceStickerCommand{}
+	case VOTE_BACKDROP_COMMAND_TAG:
+		out = &VoteBackdropCommand{}
+	case UNDO_STICKER_COMMAND_TAG:
+		out = &UndoStickerCommand{}
 	case SET_PAINTING_COMMAND_TAG:
 		out = &SetPaintingCommand{}
 	case ENTER_SESSION_EVENT_TAG:
@@ -64,18 +118,42 @@ func DeserializeMessage(data []byte) (Message, error) {
 		out = &JoinSessionFailedEvent{}
 	case KICKED_EVENT_TAG:
 		out = &KickedEvent{}
+	case SETTINGS_CHANGED_EVENT_TAG:
+		out = &SettingsChangedEvent{}
+	case PROMPT_PACKS_EVENT_TAG:
+		out = &PromptPacksEvent{}
+	case STICKER_PACKS_EVENT_TAG:
+		out = &StickerPacksEvent{}
+	case BACKDROP_PACKS_EVENT_TAG:
+		out = &BackdropPacksEvent{}
+	case SUBMITTED_PROMPTS_EVENT_TAG:
+		out = &SubmittedPromptsEvent{}
+	case INVITE_CREATED_EVENT_TAG:
+		out = &InviteCreatedEvent{}
+	case PUBLIC_SESSIONS_EVENT_TAG:
+		out = &PublicSessionsEvent{}
 	case CHANGE_GAME_VIEW_EVENT_TAG:
 		out = &ChangeGameViewEvent{}
 	case TIMER_CHANGED_EVENT_TAG:
 		out = &TimerChangedEvent{}
-	case CHANGE_TOOL_MODIFIER_EVENT_TAG:
-		out = &ChangeToolModifierEvent{}
+	case ACTIVE_EFFECTS_CHANGED_EVENT_TAG:
+		out = &ActiveEffectsChangedEvent{}
 	case PAINTING_CHANGED_EVENT_TAG:
 		out = &PaintingChangedEvent{}
 	case PLAYERS_CHANGED_EVENT_TAG:
 		out = &PlayersChangedEvent{}
 	case PLAYER_READY_CHANGED_EVENT_TAG:
 		out = &PlayerReadyChangedEvent{}
+	case CHAT_MESSAGE_EVENT_TAG:
+		out = &ChatMessageEvent{}
+	case REACTION_EVENT_TAG:
+		out = &ReactionEvent{}
+	case RATING_PROGRESS_EVENT_TAG:
+		out = &RatingProgressEvent{}
+	case LEADERBOARD_EVENT_TAG:
+		out = &LeaderboardEvent{}
+	case SCOREBOARD_EVENT_TAG:
+		out = &ScoreboardEvent{}
 	case POP_UP_EVENT_TAG:
 		out = &PopUpEvent{}
 	case DEBUG_MESSAGE_EVENT_TAG:
@@ -97,6 +175,11 @@ type Sticker struct {
 	Id string `json:"id"`
 	X float32 `json:"x"`
 	Y float32 `json:"y"`
+	Author string `json:"author"`
+	Scale float32 `json:"scale"`
+	Rotation float32 `json:"rotation"`
+	Flip bool `json:"flip"`
+	Z int `json:"z"`
 }
 
 type GameView string
@@ -142,11 +225,31 @@ const (
 	USER_ACTION_SET_READY UserAction = "set-ready"
 	USER_ACTION_SET_NOT_READY UserAction = "set-not-ready"
 	USER_ACTION_LEAVE_GALLERY UserAction = "leave-gallery"
+	USER_ACTION_SKIP_TURN UserAction = "skip-turn"
 )
 var ALL_USER_ACTION_ITEMS = []UserAction{
 	"set-ready",
 	"set-not-ready",
 	"leave-gallery",
+	"skip-turn",
+}
+
+type Reaction string
+const (
+	REACTION_LAUGH Reaction = "laugh"
+	REACTION_LOVE Reaction = "love"
+	REACTION_SHOCK Reaction = "shock"
+	REACTION_APPLAUSE Reaction = "applause"
+	REACTION_FIRE Reaction = "fire"
+	REACTION_POOP Reaction = "poop"
+)
+var ALL_REACTION_ITEMS = []Reaction{
+	"laugh",
+	"love",
+	"shock",
+	"applause",
+	"fire",
+	"poop",
 }
 
 type Backdrop string
@@ -165,18 +268,146 @@ var ALL_BACKDROP_ITEMS = []Backdrop{
 	"desert",
 }
 
+type GameMode string
+const (
+	GAME_MODE_CLASSIC GameMode = "classic"
+	GAME_MODE_GUESSING GameMode = "guessing"
+)
+var ALL_GAME_MODE_ITEMS = []GameMode{
+	"classic",
+	"guessing",
+}
+
+type TurnOrder string
+const (
+	TURN_ORDER_ROTATION TurnOrder = "rotation"
+	TURN_ORDER_RANDOM TurnOrder = "random"
+)
+var ALL_TURN_ORDER_ITEMS = []TurnOrder{
+	"rotation",
+	"random",
+}
+
+type ContentRating string
+const (
+	CONTENT_RATING_EVERYONE ContentRating = "everyone"
+	CONTENT_RATING_TEEN ContentRating = "teen"
+	CONTENT_RATING_MATURE ContentRating = "mature"
+)
+var ALL_CONTENT_RATING_ITEMS = []ContentRating{
+	"everyone",
+	"teen",
+	"mature",
+}
+
+type PromptPackInfo struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Language string `json:"language"`
+	Category string `json:"category"`
+	Rating ContentRating `json:"rating"`
+	Prompts int `json:"prompts"`
+}
+
+type StickerInfo struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Url string `json:"url"`
+	Scale float32 `json:"scale"`
+}
+
+type StickerPackInfo struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Category string `json:"category"`
+	Stickers []StickerInfo `json:"stickers"`
+}
+
+type BackdropInfo struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Url string `json:"url"`
+	Themes []string `json:"themes"`
+	Categories []string `json:"categories"`
+}
+
+type BackdropPackInfo struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Backdrops []BackdropInfo `json:"backdrops"`
+}
+
+type SessionSettings struct {
+	MaxPlayers int `json:"maxPlayers"`
+	PaintingTime int `json:"paintingTime"`
+	Mode GameMode `json:"mode"`
+	TurnOrder TurnOrder `json:"turnOrder"`
+	PaintTurns int `json:"paintTurns"`
+	Rounds int `json:"rounds"`
+	Teams bool `json:"teams"`
+	Stickers int `json:"stickers"`
+	PromptPacks []string `json:"promptPacks"`
+	StickerPacks []string `json:"stickerPacks"`
+	BackdropPacks []string `json:"backdropPacks"`
+	BackdropVote bool `json:"backdropVote"`
+	Effects []Effect `json:"effects"`
+}
+
+type PublicSessionInfo struct {
+	SessionId string `json:"sessionId"`
+	HostName string `json:"hostName"`
+	Players int `json:"players"`
+	Settings SessionSettings `json:"settings"`
+}
+
 type CreateSessionCommand struct {
 	NickName string `json:"nickName"`
+	RequireSecret bool `json:"requireSecret"`
+	Public bool `json:"public"`
+	Password string `json:"password"`
 }
 
 type JoinSessionCommand struct {
 	NickName string `json:"nickName"`
 	SessionId string `json:"sessionId"`
+	JoinSecret string `json:"joinSecret"`
+	Password string `json:"password"`
+	Invite string `json:"invite"`
 }
 
 type LeaveSessionCommand struct {
 }
 
+type CreateInviteCommand struct {
+	Password string `json:"password"`
+	SetPassword bool `json:"setPassword"`
+}
+
+type ListPublicSessionsCommand struct {
+}
+
+type QuickMatchCommand struct {
+	NickName string `json:"nickName"`
+}
+
+type ChangeSettingsCommand struct {
+	Settings SessionSettings `json:"settings"`
+}
+
+type SubmitPromptCommand struct {
+	Prompt string `json:"prompt"`
+}
+
+type AddBotCommand struct {
+}
+
+type RemoveBotCommand struct {
+	Player string `json:"player"`
+}
+
+type ResetScoreboardCommand struct {
+}
+
 type UserCommand struct {
 	Action UserAction `json:"action"`
 }
@@ -185,10 +416,36 @@ type VoteCommand struct {
 	Option string `json:"option"`
 }
 
+type ChatCommand struct {
+	Message string `json:"message"`
+}
+
+type ReactCommand struct {
+	Reaction Reaction `json:"reaction"`
+}
+
+type GuessCommand struct {
+	Guess string `json:"guess"`
+}
+
+type VoteStickerCommand struct {
+	Sticker int `json:"sticker"`
+}
+
 type PlaceStickerCommand struct {
 	Sticker string `json:"sticker"`
 	X float32 `json:"x"`
 	Y float32 `json:"y"`
+	Scale float32 `json:"scale"`
+	Rotation float32 `json:"rotation"`
+	Flip bool `json:"flip"`
+}
+
+type VoteBackdropCommand struct {
+	Backdrop string `json:"backdrop"`
+}
+
+type UndoStickerCommand struct {
 }
 
 type SetPaintingCommand struct {
@@ -197,6 +454,8 @@ type SetPaintingCommand struct {
 
 type EnterSessionEvent struct {
 	SessionId string `json:"sessionId"`
+	JoinSecret string `json:"joinSecret"`
+	PlayerId string `json:"playerId"`
 }
 
 type JoinSessionFailedEvent struct {
@@ -207,13 +466,42 @@ type KickedEvent struct {
 	Reason string `json:"reason"`
 }
 
+type SettingsChangedEvent struct {
+	Settings SessionSettings `json:"settings"`
+}
+
+type PromptPacksEvent struct {
+	Packs []PromptPackInfo `json:"packs"`
+}
+
+type StickerPacksEvent struct {
+	Packs []StickerPackInfo `json:"packs"`
+}
+
+type BackdropPacksEvent struct {
+	Packs []BackdropPackInfo `json:"packs"`
+}
+
+type SubmittedPromptsEvent struct {
+	Prompts []string `json:"prompts"`
+}
+
+type InviteCreatedEvent struct {
+	Invite string `json:"invite"`
+}
+
+type PublicSessionsEvent struct {
+	Sessions []PublicSessionInfo `json:"sessions"`
+}
+
 type Painting struct {
 	Prompt string `json:"prompt"`
 	Graphics Graphics `json:"graphics"`
-	Backdrop Backdrop `json:"backdrop"`
+	Backdrop string `json:"backdrop"`
 	Stickers []Sticker `json:"stickers"`
 	Winner bool `json:"winner"`
-	Score int `json:"score"`
+	Score float32 `json:"score"`
+	Votes int `json:"votes"`
 }
 
 type ChangeGameViewEvent struct {
@@ -223,29 +511,98 @@ type ChangeGameViewEvent struct {
 	VotePrompt string `json:"votePrompt"`
 	VoteOptions []string `json:"voteOptions"`
 	Announcer string `json:"announcer"`
+	CanSkip bool `json:"canSkip"`
+	CanUndoSticker bool `json:"canUndoSticker"`
+	CanGuess bool `json:"canGuess"`
+	CanReact bool `json:"canReact"`
+	BackdropOptions []string `json:"backdropOptions"`
 }
 
 type TimerChangedEvent struct {
 	SecondsLeft int `json:"secondsLeft"`
 }
 
-type ChangeToolModifierEvent struct {
-	Modifier Effect `json:"modifier"`
-	Duration int `json:"duration"`
+type ActiveEffect struct {
+	Effect Effect `json:"effect"`
+	Parameters map[string]float32 `json:"parameters"`
+}
+
+type ActiveEffectsChangedEvent struct {
+	Effects []ActiveEffect `json:"effects"`
 }
 
 type PaintingChangedEvent struct {
 	Graphics Graphics `json:"graphics"`
 }
 
+type PlayerInfo struct {
+	Id string `json:"id"`
+	Name string `json:"name"`
+	Host bool `json:"host"`
+	Ready bool `json:"ready"`
+	Connected bool `json:"connected"`
+	Bot bool `json:"bot"`
+	Score float32 `json:"score"`
+}
+
 type PlayersChangedEvent struct {
-	Players []string `json:"players"`
-	AddedPlayer *string `json:"addedPlayer"`
-	RemovedPlayer *string `json:"removedPlayer"`
+	Players []PlayerInfo `json:"players"`
+	AddedPlayer *PlayerInfo `json:"addedPlayer"`
+	RemovedPlayer *PlayerInfo `json:"removedPlayer"`
 }
 
 type PlayerReadyChangedEvent struct {
-	Players map[string]bool `json:"players"`
+	Players []PlayerInfo `json:"players"`
+}
+
+type ChatMessageEvent struct {
+	Sender PlayerInfo `json:"sender"`
+	Message string `json:"message"`
+}
+
+type ReactionEvent struct {
+	Sender PlayerInfo `json:"sender"`
+	Reaction Reaction `json:"reaction"`
+}
+
+type RatingProgressEvent struct {
+	Votes int `json:"votes"`
+	Voters int `json:"voters"`
+}
+
+type LeaderboardEntry struct {
+	Player PlayerInfo `json:"player"`
+	Rank int `json:"rank"`
+	Total float32 `json:"total"`
+	Painting float32 `json:"painting"`
+	Trolling float32 `json:"trolling"`
+	Stickers float32 `json:"stickers"`
+	Guessing float32 `json:"guessing"`
+}
+
+type TeamEntry struct {
+	Members []PlayerInfo `json:"members"`
+	Rank int `json:"rank"`
+	Total float32 `json:"total"`
+}
+
+type LeaderboardEvent struct {
+	Entries []LeaderboardEntry `json:"entries"`
+	Teams []TeamEntry `json:"teams"`
+}
+
+type ScoreboardEntry struct {
+	Player PlayerInfo `json:"player"`
+	Rank int `json:"rank"`
+	Matches int `json:"matches"`
+	Wins int `json:"wins"`
+	Stars float32 `json:"stars"`
+	BestPainting *Painting `json:"bestPainting"`
+}
+
+type ScoreboardEvent struct {
+	Matches int `json:"matches"`
+	Entries []ScoreboardEntry `json:"entries"`
 }
 
 type PopUpEvent struct {
@@ -282,6 +639,70 @@ func (item *LeaveSessionCommand) FixNils() Message {
 	return &copy
 }
 
+func (item *CreateInviteCommand) GetJsonType() string {
+	return "create-invite-command"
+}
+func (item *CreateInviteCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *ListPublicSessionsCommand) GetJsonType() string {
+	return "list-public-sessions-command"
+}
+func (item *ListPublicSessionsCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *QuickMatchCommand) GetJsonType() string {
+	return "quick-match-command"
+}
+func (item *QuickMatchCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *ChangeSettingsCommand) GetJsonType() string {
+	return "change-settings-command"
+}
+func (item *ChangeSettingsCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *SubmitPromptCommand) GetJsonType() string {
+	return "submit-prompt-command"
+}
+func (item *SubmitPromptCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *AddBotCommand) GetJsonType() string {
+	return "add-bot-command"
+}
+func (item *AddBotCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *RemoveBotCommand) GetJsonType() string {
+	return "remove-bot-command"
+}
+func (item *RemoveBotCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *ResetScoreboardCommand) GetJsonType() string {
+	return "reset-scoreboard-command"
+}
+func (item *ResetScoreboardCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
 func (item *UserCommand) GetJsonType() string {
 	return "user-command"
 }
@@ -298,6 +719,38 @@ func (item *VoteCommand) FixNils() Message {
 	return &copy
 }
 
+func (item *ChatCommand) GetJsonType() string {
+	return "chat-command"
+}
+func (item *ChatCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *ReactCommand) GetJsonType() string {
+	return "react-command"
+}
+func (item *ReactCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *GuessCommand) GetJsonType() string {
+	return "guess-command"
+}
+func (item *GuessCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *VoteStickerCommand) GetJsonType() string {
+	return "vote-sticker-command"
+}
+func (item *VoteStickerCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
 func (item *PlaceStickerCommand) GetJsonType() string {
 	return "place-sticker-command"
 }
@@ -306,6 +759,22 @@ func (item *PlaceStickerCommand) FixNils() Message {
 	return &copy
 }
 
+func (item *VoteBackdropCommand) GetJsonType() string {
+	return "vote-backdrop-command"
+}
+func (item *VoteBackdropCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *UndoStickerCommand) GetJsonType() string {
+	return "undo-sticker-command"
+}
+func (item *UndoStickerCommand) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
 func (item *SetPaintingCommand) GetJsonType() string {
 	return "set-painting-command"
 }
@@ -338,6 +807,77 @@ func (item *KickedEvent) FixNils() Message {
 	return &copy
 }
 
+func (item *SettingsChangedEvent) GetJsonType() string {
+	return "settings-changed-event"
+}
+func (item *SettingsChangedEvent) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *PromptPacksEvent) GetJsonType() string {
+	return "prompt-packs-event"
+}
+func (item *PromptPacksEvent) FixNils() Message {
+	copy := *item
+	if copy.Packs == nil {
+		copy.Packs = []PromptPackInfo{}
+	}
+	return &copy
+}
+
+func (item *StickerPacksEvent) GetJsonType() string {
+	return "sticker-packs-event"
+}
+func (item *StickerPacksEvent) FixNils() Message {
+	copy := *item
+	if copy.Packs == nil {
+		copy.Packs = []StickerPackInfo{}
+	}
+	return &copy
+}
+
+func (item *BackdropPacksEvent) GetJsonType() string {
+	return "backdrop-packs-event"
+}
+func (item *BackdropPacksEvent) FixNils() Message {
+	copy := *item
+	if copy.Packs == nil {
+		copy.Packs = []BackdropPackInfo{}
+	}
+	return &copy
+}
+
+func (item *SubmittedPromptsEvent) GetJsonType() string {
+	return "submitted-prompts-event"
+}
+func (item *SubmittedPromptsEvent) FixNils() Message {
+	copy := *item
+	if copy.Prompts == nil {
+		copy.Prompts = []string{}
+	}
+	return &copy
+}
+
+func (item *InviteCreatedEvent) GetJsonType() string {
+	return "invite-created-event"
+}
+func (item *InviteCreatedEvent) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *PublicSessionsEvent) GetJsonType() string {
+	return "public-sessions-event"
+}
+func (item *PublicSessionsEvent) FixNils() Message {
+	copy := *item
+	if copy.Sessions == nil {
+		copy.Sessions = []PublicSessionInfo{}
+	}
+	return &copy
+}
+
 func (item *ChangeGameViewEvent) GetJsonType() string {
 	return "change-game-view-event"
 }
@@ -349,6 +889,9 @@ func (item *ChangeGameViewEvent) FixNils() Message {
 	if copy.VoteOptions == nil {
 		copy.VoteOptions = []string{}
 	}
+	if copy.BackdropOptions == nil {
+		copy.BackdropOptions = []string{}
+	}
 	return &copy
 }
 
@@ -360,11 +903,14 @@ func (item *TimerChangedEvent) FixNils() Message {
 	return &copy
 }
 
-func (item *ChangeToolModifierEvent) GetJsonType() string {
-	return "change-tool-modifier-event"
+func (item *ActiveEffectsChangedEvent) GetJsonType() string {
+	return "active-effects-changed-event"
 }
-func (item *ChangeToolModifierEvent) FixNils() Message {
+func (item *ActiveEffectsChangedEvent) FixNils() Message {
 	copy := *item
+	if copy.Effects == nil {
+		copy.Effects = []ActiveEffect{}
+	}
 	return &copy
 }
 
@@ -382,7 +928,7 @@ func (item *PlayersChangedEvent) GetJsonType() string {
 func (item *PlayersChangedEvent) FixNils() Message {
 	copy := *item
 	if copy.Players == nil {
-		copy.Players = []string{}
+		copy.Players = []PlayerInfo{}
 	}
 	return &copy
 }
@@ -393,7 +939,56 @@ func (item *PlayerReadyChangedEvent) GetJsonType() string {
 func (item *PlayerReadyChangedEvent) FixNils() Message {
 	copy := *item
 	if copy.Players == nil {
-		copy.Players = map[string]bool{}
+		copy.Players = []PlayerInfo{}
+	}
+	return &copy
+}
+
+func (item *ChatMessageEvent) GetJsonType() string {
+	return "chat-message-event"
+}
+func (item *ChatMessageEvent) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *ReactionEvent) GetJsonType() string {
+	return "reaction-event"
+}
+func (item *ReactionEvent) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *RatingProgressEvent) GetJsonType() string {
+	return "rating-progress-event"
+}
+func (item *RatingProgressEvent) FixNils() Message {
+	copy := *item
+	return &copy
+}
+
+func (item *LeaderboardEvent) GetJsonType() string {
+	return "leaderboard-event"
+}
+func (item *LeaderboardEvent) FixNils() Message {
+	copy := *item
+	if copy.Entries == nil {
+		copy.Entries = []LeaderboardEntry{}
+	}
+	if copy.Teams == nil {
+		copy.Teams = []TeamEntry{}
+	}
+	return &copy
+}
+
+func (item *ScoreboardEvent) GetJsonType() string {
+	return "scoreboard-event"
+}
+func (item *ScoreboardEvent) FixNils() Message {
+	copy := *item
+	if copy.Entries == nil {
+		copy.Entries = []ScoreboardEntry{}
 	}
 	return &copy
 }
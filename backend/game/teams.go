@@ -0,0 +1,108 @@
+package game
+
+import (
+	mrand "math/rand"
+	"sort"
+	"strings"
+)
+
+// Splits the players into the teams that paint together. Without team
+// mode, or if there wouldn't be anyone left to troll, everyone paints alone.
+func formTeams(rng *mrand.Rand, players []*Player, team_mode bool) [][]*Player {
+	order := make([]*Player, len(players))
+	copy(order, players)
+	rng.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+
+	size := 1
+	if team_mode && len(order) >= 3 {
+		size = 2
+	}
+
+	teams := make([][]*Player, 0, len(order))
+	for start := 0; start < len(order); start += size {
+		end := start + size
+		if end > len(order) {
+			end = len(order) // one player is left over and paints alone
+		}
+		teams = append(teams, order[start:end])
+	}
+	return teams
+}
+
+// Returns the members of the team that are still in the session.
+func (session *Session) presentMembers(team []*Player) []*Player {
+	present := make([]*Player, 0, len(team))
+	for _, player := range team {
+		if session.hasPlayer(player) {
+			present = append(present, player)
+		}
+	}
+	return present
+}
+
+func teamName(team []*Player) string {
+	names := make([]string, len(team))
+	for i, player := range team {
+		names[i] = player.NickName
+	}
+	return strings.Join(names, " & ")
+}
+
+// Combines the strokes of all painters of a team into one painting. The
+// server doesn't care about the graphics except for their list of paths,
+// the cursor is taken from the painter that painted last.
+func mergeGraphics(parts []Graphics, latest Graphics) Graphics {
+	paths := make([]interface{}, 0)
+	for _, part := range parts {
+		fields, ok := part.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if part_paths, ok := fields["paths"].([]interface{}); ok {
+			paths = append(paths, part_paths...)
+		}
+	}
+
+	merged := map[string]interface{}{
+		"paths": paths,
+	}
+	if fields, ok := latest.(map[string]interface{}); ok {
+		merged["mx"] = fields["mx"]
+		merged["my"] = fields["my"]
+	}
+	return merged
+}
+
+// Ranks the teams of the match by the average total points of their
+// members.
+func (session *Session) teamLeaderboard() []TeamEntry {
+	if session.matchTeams == nil {
+		return []TeamEntry{}
+	}
+
+	entries := make([]TeamEntry, len(session.matchTeams))
+	for i, team := range session.matchTeams {
+		entries[i].Members = make([]PlayerInfo, len(team))
+		for j, player := range team {
+			entries[i].Members[j] = session.playerInfo(player)
+			entries[i].Total += session.scoreCard(player).total()
+		}
+		entries[i].Total /= float32(len(team))
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Total > entries[j].Total
+	})
+
+	for i := range entries {
+		if i > 0 && entries[i].Total == entries[i-1].Total {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,60 @@
+package game
+
+import (
+	mrand "math/rand"
+	"sort"
+)
+
+// Returns the indices of the teams in the order they paint, according to
+// the turn order the host selected.
+func planTurns(rng *mrand.Rand, teams int, settings SessionSettings) []int {
+	turns := make([]int, 0, teams*settings.PaintTurns)
+
+	// Nobody should paint twice in a row if it can be avoided:
+	repeats := func(team int) bool {
+		return teams > 1 && len(turns) > 0 && turns[len(turns)-1] == team
+	}
+
+	switch settings.TurnOrder {
+	case TURN_ORDER_RANDOM:
+		for len(turns) < settings.Rounds {
+			team := rng.Intn(teams)
+			if repeats(team) {
+				continue
+			}
+			turns = append(turns, team)
+		}
+
+	default:
+		for rotation := 0; rotation < settings.PaintTurns; rotation++ {
+			order := rng.Perm(teams)
+			if repeats(order[0]) {
+				order[0], order[len(order)-1] = order[len(order)-1], order[0]
+			}
+			turns = append(turns, order...)
+		}
+	}
+
+	return turns
+}
+
+// Picks up to `count` of the players, preferring those that had the fewest
+// turns so far, and counts the turn for them.
+func pickFairly(rng *mrand.Rand, players []*Player, turns map[*Player]int, count int) []*Player {
+	order := make([]*Player, len(players))
+	copy(order, players)
+	rng.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+	sort.SliceStable(order, func(i, j int) bool {
+		return turns[order[i]] < turns[order[j]]
+	})
+
+	if count > len(order) {
+		count = len(order)
+	}
+	for _, player := range order[:count] {
+		turns[player] += 1
+	}
+	return order[:count]
+}
@@ -2,6 +2,13 @@ module random-projects.net/crayos-backend
 
 go 1.18
 
-require github.com/gorilla/websocket v1.5.1
+require (
+	github.com/gorilla/websocket v1.5.1
+	golang.org/x/text v0.13.0
+	random-projects.net/crayos-frontend v0.0.0-00010101000000-000000000000
+)
 
 require golang.org/x/net v0.17.0 // indirect
+
+// The frontend is embedded into the backend binary.
+replace random-projects.net/crayos-frontend => ../frontend
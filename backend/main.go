@@ -19,7 +19,7 @@ func main() {
 	log.Println("Ready.")
 
 	if *meta.DEBUG_MODE {
-		default_session := game.CreateSession(nil)
+		default_session := game.CreateSession(nil, game.SessionOptions{})
 
 		game.SetDebugSession(default_session)
 
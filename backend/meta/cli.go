@@ -1,6 +1,19 @@
 package meta
 
-import "flag"
+import (
+	"flag"
+	"time"
+)
 
 var FLAG_ADDR = flag.String("addr", ":8080", "http service address")
 var DEBUG_MODE = flag.Bool("debug", false, "Enables debug mode (default session + no session death)")
+var ALLOWED_ORIGINS = flag.String("allowed-origins", "", "Comma separated list of additional origins that may open a websocket, '*' allows all")
+var TLS_CERT_FILE = flag.String("tls-cert", "", "Certificate file for serving HTTPS, reloaded when it changes")
+var TLS_KEY_FILE = flag.String("tls-key", "", "Private key file for serving HTTPS, reloaded when it changes")
+var FLAG_REDIRECT_ADDR = flag.String("redirect-addr", "", "http service address that redirects to HTTPS (requires -tls-cert)")
+var HSTS_MAX_AGE = flag.Duration("hsts-max-age", 180*24*time.Hour, "max-age of the Strict-Transport-Security header when serving HTTPS, 0 disables it")
+var FRONTEND_DIR = flag.String("frontend-dir", "", "Serves the frontend from this directory instead of the embedded files (for development)")
+var CHAT_BLOCKLIST_FILE = flag.String("chat-blocklist", "", "File with words that are censored in the chat, one per line")
+var PROMPT_PACKS_DIR = flag.String("prompt-packs", "", "Directory with additional prompt packs (*.json)")
+var BACKDROP_PACKS_DIR = flag.String("backdrop-packs", "", "Directory with additional backdrop packs (one directory with a pack.json and the images per pack)")
+var STICKER_PACKS_DIR = flag.String("sticker-packs", "", "Directory with additional sticker packs (one directory with a pack.json and the images per pack)")
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"random-projects.net/crayos-backend/meta"
+	frontend "random-projects.net/crayos-frontend"
+)
+
+const (
+	// Versioned assets never change, so browsers may keep them forever.
+	cacheControlImmutable = "public, max-age=31536000, immutable"
+
+	// Everything else must be revalidated with the ETag.
+	cacheControlRevalidate = "no-cache"
+)
+
+type frontendAsset struct {
+	content []byte
+	hash    string
+}
+
+var frontendFiles fs.FS
+
+// Precomputed assets of the embedded frontend. nil when serving from disk,
+// so changes show up without a restart.
+var frontendAssets map[string]*frontendAsset
+
+// Matches references to local files in HTML documents.
+var assetReferencePattern = regexp.MustCompile(`(src|href)="([^":?#]+)"`)
+
+func setupFrontend() {
+	if *meta.FRONTEND_DIR != "" {
+		log.Println("Serving frontend from", *meta.FRONTEND_DIR)
+		frontendFiles = os.DirFS(*meta.FRONTEND_DIR)
+		return
+	}
+
+	frontendFiles = frontend.Files
+	frontendAssets = make(map[string]*frontendAsset)
+
+	err := fs.WalkDir(frontendFiles, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		asset, err := readFrontendAsset(name)
+		if err != nil {
+			return err
+		}
+		frontendAssets[name] = asset
+		return nil
+	})
+	if err != nil {
+		log.Fatalln("failed to load embedded frontend: ", err)
+	}
+
+	// Let the documents reference the exact version of each asset, so the
+	// assets themselves can be cached forever:
+	for name, asset := range frontendAssets {
+		if path.Ext(name) == ".html" {
+			asset.content = versionAssetReferences(name, asset.content)
+			asset.hash = hashContent(asset.content)
+		}
+	}
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:8])
+}
+
+func readFrontendAsset(name string) (*frontendAsset, error) {
+	content, err := fs.ReadFile(frontendFiles, name)
+	if err != nil {
+		return nil, err
+	}
+	return &frontendAsset{
+		content: content,
+		hash:    hashContent(content),
+	}, nil
+}
+
+func versionAssetReferences(document string, content []byte) []byte {
+	return assetReferencePattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		parts := assetReferencePattern.FindSubmatch(match)
+		reference := string(parts[2])
+
+		asset, ok := frontendAssets[path.Join(path.Dir(document), reference)]
+		if !ok {
+			return match
+		}
+		return []byte(string(parts[1]) + `="` + reference + "?v=" + asset.hash + `"`)
+	})
+}
+
+func serveFrontend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	var asset *frontendAsset
+	if frontendAssets != nil {
+		asset = frontendAssets[name]
+	} else if fs.ValidPath(name) {
+		asset, _ = readFrontendAsset(name) // directories fail as well
+	}
+	if asset == nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	if frontendAssets != nil && r.URL.Query().Get("v") == asset.hash {
+		w.Header().Set("Cache-Control", cacheControlImmutable)
+	} else {
+		w.Header().Set("Cache-Control", cacheControlRevalidate)
+	}
+	w.Header().Set("ETag", `"`+asset.hash+`"`)
+
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(asset.content))
+}
+
+// Serves the images of the sticker or backdrop packs loaded at startup.
+// `lookup` returns the file of an image of a pack.
+func servePackImages(prefix string, lookup func(pack string, file string) (string, bool)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		pack, file, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		file_path, ok := lookup(pack, file)
+		if !ok {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Cache-Control", cacheControlRevalidate)
+		http.ServeFile(w, r, file_path)
+	}
+}
@@ -5,12 +5,12 @@ import (
 	_ "embed"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"random-projects.net/crayos-backend/game"
 	"random-projects.net/crayos-backend/meta"
-
-	"github.com/gorilla/websocket"
 )
 
 //go:embed "api.html"
@@ -20,51 +20,92 @@ func serveApi(w http.ResponseWriter, r *http.Request) {
 	http.ServeContent(w, r, "", time.Now(), bytes.NewReader(WWW_API_CONTENT))
 }
 
-func serveHome(w http.ResponseWriter, r *http.Request) {
-	log.Println(r.URL)
-	if r.URL.Path != "/" {
-		http.Error(w, "Not found", http.StatusNotFound)
-		return
-	}
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-	http.ServeFile(w, r, "home.html")
-}
+// Origins that may connect players in addition to our own host.
+var allowedOrigins []string
 
-var websocketUpgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(*http.Request) bool {
+// Prevents other sites from hijacking game sessions through the browser
+// of a player.
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// Browsers always send an origin, so this isn't a cross-site request.
 		return true
-	},
-}
+	}
 
-func acceptPlayerWebsocket(w http.ResponseWriter, r *http.Request) {
+	origin_url, err := url.Parse(origin)
+	if err == nil && strings.EqualFold(origin_url.Host, r.Host) {
+		return true
+	}
 
-	conn, err := websocketUpgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Println(err)
-		return
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
 	}
 
-	game.CreatePlayer(conn)
+	log.Println("rejected request from foreign origin", origin)
+	return false
 }
 
 var server *http.Server
+var redirect_server *http.Server // only used when serving HTTPS
 
 func Setup() {
-	http.HandleFunc("/", serveApi)
+	for _, origin := range strings.Split(*meta.ALLOWED_ORIGINS, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin != "" {
+			allowedOrigins = append(allowedOrigins, strings.TrimSuffix(origin, "/"))
+		}
+	}
+
+	setupFrontend()
+
+	http.HandleFunc("/", serveFrontend)
+	http.HandleFunc(game.STICKER_PACKS_URL, servePackImages(game.STICKER_PACKS_URL, game.StickerFile))
+	http.HandleFunc(game.BACKDROP_PACKS_URL, servePackImages(game.BACKDROP_PACKS_URL, game.BackdropFile))
 	http.HandleFunc("/api", serveApi)
 	http.HandleFunc("/ws", acceptPlayerWebsocket)
+	http.HandleFunc("/sse", acceptPlayerEventStream)
+	http.HandleFunc("/sse/command", receivePlayerCommand)
 
 	server = &http.Server{
 		Addr:              *meta.FLAG_ADDR,
 		ReadHeaderTimeout: 3 * time.Second,
 	}
+
+	if tlsEnabled() {
+		tls_config, err := createTlsConfig()
+		if err != nil {
+			log.Fatalln("failed to set up TLS: ", err)
+		}
+		server.TLSConfig = tls_config
+
+		if *meta.HSTS_MAX_AGE > 0 {
+			server.Handler = withHsts(http.DefaultServeMux)
+		}
+
+		if *meta.FLAG_REDIRECT_ADDR != "" {
+			redirect_server = createRedirectServer(*meta.FLAG_ADDR)
+		}
+	} else if *meta.FLAG_REDIRECT_ADDR != "" {
+		log.Fatalln("-redirect-addr requires HTTPS to be configured")
+	}
 }
 
 func Run() error {
-	return server.ListenAndServe()
+	if server.TLSConfig == nil {
+		return server.ListenAndServe()
+	}
+
+	if redirect_server != nil {
+		go func() {
+			err := redirect_server.ListenAndServe()
+			if err != nil {
+				log.Fatal("redirect server: ", err)
+			}
+		}()
+	}
+
+	// Certificates are provided by TLSConfig.GetCertificate
+	return server.ListenAndServeTLS("", "")
 }
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"random-projects.net/crayos-backend/game"
+)
+
+// Fallback for networks that block websocket upgrades: events are streamed
+// to the client as server-sent events, commands are posted to
+// /sse/command?token=... with the token announced at the start of the stream.
+
+const (
+	// Maximum size of a posted command.
+	maxCommandSize = 1 << 20
+
+	// Keeps proxies from closing idle streams.
+	eventStreamKeepAlive = 30 * time.Second
+)
+
+type eventStreamTransport struct {
+	token string
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func (transport *eventStreamTransport) Close() {
+	transport.closeOnce.Do(func() {
+		close(transport.done)
+	})
+}
+
+var (
+	eventStreamPlayersMu sync.Mutex
+	eventStreamPlayers   = map[string]*game.Player{}
+)
+
+func createEventStreamToken() string {
+	var token [16]byte
+	if _, err := rand.Read(token[:]); err != nil {
+		log.Fatalln("failed to create event stream token: ", err)
+	}
+	return hex.EncodeToString(token[:])
+}
+
+func acceptPlayerEventStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !checkOrigin(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	transport := &eventStreamTransport{
+		token: createEventStreamToken(),
+		done:  make(chan struct{}),
+	}
+	player := game.CreatePlayer(transport)
+
+	eventStreamPlayersMu.Lock()
+	eventStreamPlayers[transport.token] = player
+	eventStreamPlayersMu.Unlock()
+
+	defer func() {
+		eventStreamPlayersMu.Lock()
+		delete(eventStreamPlayers, transport.token)
+		eventStreamPlayersMu.Unlock()
+
+		player.Close()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // disables buffering in nginx
+
+	fmt.Fprintf(w, "event: token\ndata: %s\n\n", transport.token)
+	flusher.Flush()
+
+	keep_alive := time.NewTicker(eventStreamKeepAlive)
+	defer keep_alive.Stop()
+
+	for {
+		select {
+		case <-player.OutboundSignal():
+			messages, ok := player.TakeOutbound()
+			if !ok {
+				// The player was closed or dropped.
+				return
+			}
+
+			for _, message := range messages {
+				// Serialized messages never contain line breaks.
+				_, err := fmt.Fprintf(w, "data: %s\n\n", message)
+				if err != nil {
+					log.Println("failed to send message to client: ", err)
+					return
+				}
+			}
+			flusher.Flush()
+
+		case <-keep_alive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-transport.done:
+			return
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func receivePlayerCommand(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !checkOrigin(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	eventStreamPlayersMu.Lock()
+	player, ok := eventStreamPlayers[r.URL.Query().Get("token")]
+	eventStreamPlayersMu.Unlock()
+
+	if !ok {
+		http.Error(w, "Unknown player", http.StatusNotFound)
+		return
+	}
+
+	raw_message, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCommandSize))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	err = player.HandleInbound(raw_message)
+	if err != nil {
+		log.Println("dropping client: ", err)
+		player.Close()
+		http.Error(w, "Bad command", http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"random-projects.net/crayos-backend/meta"
+)
+
+// How often the certificate files are checked for changes.
+const certificatePollInterval = 10 * time.Second
+
+// Serves the certificate from the configured files and reloads it when
+// one of the files changes, so certificate rotation doesn't need a restart.
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+}
+
+func createCertificateReloader(cert_file string, key_file string) (*certificateReloader, error) {
+	reloader := &certificateReloader{
+		certFile: cert_file,
+		keyFile:  key_file,
+	}
+
+	_, err := reloader.reloadIfChanged()
+	if err != nil {
+		return nil, err
+	}
+
+	go reloader.watch()
+
+	return reloader, nil
+}
+
+func fileModTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+func (reloader *certificateReloader) reloadIfChanged() (bool, error) {
+	cert_mod_time, err := fileModTime(reloader.certFile)
+	if err != nil {
+		return false, err
+	}
+	key_mod_time, err := fileModTime(reloader.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	reloader.mu.RLock()
+	unchanged := reloader.certificate != nil &&
+		cert_mod_time.Equal(reloader.certModTime) &&
+		key_mod_time.Equal(reloader.keyModTime)
+	reloader.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(reloader.certFile, reloader.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	reloader.mu.Lock()
+	reloader.certificate = &certificate
+	reloader.certModTime = cert_mod_time
+	reloader.keyModTime = key_mod_time
+	reloader.mu.Unlock()
+
+	return true, nil
+}
+
+func (reloader *certificateReloader) watch() {
+	ticker := time.NewTicker(certificatePollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		reloaded, err := reloader.reloadIfChanged()
+		if err != nil {
+			// Keep the old certificate, the files might be in the middle of
+			// being replaced.
+			log.Println("failed to reload certificate: ", err)
+		} else if reloaded {
+			log.Println("Reloaded certificate", reloader.certFile)
+		}
+	}
+}
+
+func (reloader *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	reloader.mu.RLock()
+	defer reloader.mu.RUnlock()
+	return reloader.certificate, nil
+}
+
+func tlsEnabled() bool {
+	return *meta.TLS_CERT_FILE != "" || *meta.TLS_KEY_FILE != ""
+}
+
+func createTlsConfig() (*tls.Config, error) {
+	if *meta.TLS_CERT_FILE == "" || *meta.TLS_KEY_FILE == "" {
+		return nil, fmt.Errorf("both -tls-cert and -tls-key are required for HTTPS")
+	}
+
+	reloader, err := createCertificateReloader(*meta.TLS_CERT_FILE, *meta.TLS_KEY_FILE)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}, nil
+}
+
+// Tells browsers to only talk HTTPS to us from now on.
+func withHsts(handler http.Handler) http.Handler {
+	header := fmt.Sprintf("max-age=%d", int64(meta.HSTS_MAX_AGE.Seconds()))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", header)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Sends all plain HTTP requests to the same resource on the HTTPS server.
+func createRedirectServer(https_addr string) *http.Server {
+	_, https_port, err := net.SplitHostPort(https_addr)
+	if err != nil {
+		log.Fatalln("invalid address", https_addr, err)
+	}
+
+	return &http.Server{
+		Addr:              *meta.FLAG_REDIRECT_ADDR,
+		ReadHeaderTimeout: 3 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, _, err := net.SplitHostPort(r.Host)
+			if err != nil {
+				host = r.Host // no port in the request
+			}
+			if https_port != "443" {
+				host = net.JoinHostPort(host, https_port)
+			}
+
+			target := "https://" + host + r.URL.RequestURI()
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+		}),
+	}
+}
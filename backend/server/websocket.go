@@ -0,0 +1,117 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"random-projects.net/crayos-backend/game"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Time allowed to write a message to the peer.
+	writeWait = 10 * time.Second
+
+	// Time allowed to read the next pong message from the peer.
+	pongWait = 60 * time.Second
+
+	// Send pings to peer with this period. Must be less than pongWait.
+	pingPeriod = (pongWait * 9) / 10
+
+	// Maximum message size allowed from peer.
+	maxMessageSize = 512
+)
+
+var websocketUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     checkOrigin,
+}
+
+type websocketTransport struct {
+	ws *websocket.Conn
+}
+
+func (transport *websocketTransport) Close() {
+	transport.ws.Close()
+}
+
+func acceptPlayerWebsocket(w http.ResponseWriter, r *http.Request) {
+
+	conn, err := websocketUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	player := game.CreatePlayer(&websocketTransport{
+		ws: conn,
+	})
+
+	go writePump(player, conn)
+	go readPump(player, conn)
+}
+
+// Pumps messages from websocket to the player.
+func readPump(player *game.Player, ws *websocket.Conn) {
+	defer player.Close()
+	// ws.SetReadLimit(maxMessageSize)
+	ws.SetReadDeadline(time.Now().Add(pongWait))
+	ws.SetPongHandler(func(string) error {
+		ws.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+	for {
+		_, raw_message, err := ws.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("websocket error: %v", err)
+			}
+			break
+		}
+
+		err = player.HandleInbound(raw_message)
+		if err != nil {
+			log.Println("dropping client: ", err)
+			return
+		}
+	}
+}
+
+// Pumps the outbound messages of the player to the websocket.
+func writePump(player *game.Player, ws *websocket.Conn) {
+	defer player.Close()
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-player.OutboundSignal():
+			messages, ok := player.TakeOutbound()
+			if !ok {
+				// The player was closed or dropped.
+				ws.SetWriteDeadline(time.Now().Add(writeWait))
+				ws.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+
+			for _, message := range messages {
+				ws.SetWriteDeadline(time.Now().Add(writeWait))
+				err := ws.WriteMessage(websocket.TextMessage, message)
+				if err != nil {
+					log.Println("failed to send message to client: ", err)
+					return
+				}
+			}
+
+		case <-ticker.C:
+			ws.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
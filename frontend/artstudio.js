@@ -7,11 +7,22 @@ const eraserRadius = 40;
 const TOOL_PENCIL = "pencil";
 const TOOL_ERASER = "eraser";
 const TIMER_SECONDS = 90;
+const STICKER_MIN_SCALE = 0.5;
+const STICKER_MAX_SCALE = 2;
+const STICKER_SCALE_STEP = 0.1;
+const STICKER_ROTATION_STEP = 15; // degrees
 
 let selectedTool = null;
 
 let stickerPreview = null;
 
+// how the next sticker is placed, kept between stickers
+let stickerTransform = {
+  scale: 1,
+  rotation: 0,
+  flip: false,
+};
+
 const palette = [
   "#FFF",
   "#e42932", // red
@@ -26,10 +37,11 @@ let selectedColor = 7;
 
 let currentPainting;
 let painterPaths = [];
+let partnerPaths = []; // team mode: what the other painter of the team painted
 let mx = -1000;
 let my = -1000;
 
-let chaosEffect = null;
+let chaosEffects = {}; // active effects, mapped to their parameters
 
 let paintingSenderInterval = null;
 function startPaintingSender() {
@@ -62,6 +74,15 @@ function setPromptSelectionEnabled(enabled) {
   document.getElementById("prompt-selection").style.display = enabled ? "block" : "none";
 }
 
+function setSkipTurnEnabled(enabled) {
+  document.getElementById("skip-turn").style.display = enabled ? "block" : "none";
+}
+
+function skipTurnClicked() {
+  sendUserCommand(UserAction.skipTurn);
+  setSkipTurnEnabled(false);
+}
+
 function setPromptOptions(prompts) {
   setPaintingPrompt("Vote for a prompt!");
   for (let i = 0; i < 3; i++) {
@@ -80,19 +101,103 @@ function setActiveSticker(sticker_id) {
       x: 0,
       y: 0,
     }
+    window.addEventListener("keydown", onStickerKeyDown);
     setInputEnabled(true);
   }
   else {
     stickerPreview = null;
+    window.removeEventListener("keydown", onStickerKeyDown);
+  }
+}
+
+// mouse wheel scales the sticker, with shift it rotates the sticker
+function onStickerWheel(e) {
+  if (!stickerPreview) {
+    return;
+  }
+  e.preventDefault();
+  const direction = e.deltaY < 0 ? 1 : -1;
+  if (e.shiftKey) {
+    stickerTransform.rotation = (stickerTransform.rotation + direction * STICKER_ROTATION_STEP + 360) % 360;
+  } else {
+    stickerTransform.scale = Math.min(STICKER_MAX_SCALE, Math.max(STICKER_MIN_SCALE,
+      stickerTransform.scale + direction * STICKER_SCALE_STEP));
+  }
+  drawPainterCanvas();
+}
+
+// R rotates, F flips the sticker
+function onStickerKeyDown(e) {
+  if (e.target instanceof HTMLInputElement) {
+    return; // typing in the chat
+  }
+  switch (e.key) {
+    case "r":
+    case "R":
+      stickerTransform.rotation = (stickerTransform.rotation + STICKER_ROTATION_STEP) % 360;
+      break;
+    case "f":
+    case "F":
+      stickerTransform.flip = !stickerTransform.flip;
+      break;
+    default:
+      return;
+  }
+  drawPainterCanvas();
+}
+
+function setBackdropOptions(options) {
+  document.getElementById("backdrop-vote").style.display = options.length > 0 ? "flex" : "none";
+  for (let i = 0; i < 3; i++) {
+    const button = document.getElementById("backdrop" + i);
+    if (options[i]) {
+      const info = getBackdropInfo(options[i]);
+      button.style.display = "block";
+      button.style.backgroundImage = "url('" + info.url + "')";
+      button.title = info.name;
+      button.onclick = () => backdropClicked(options[i]);
+    } else {
+      button.style.display = "none";
+    }
+  }
+}
+
+function backdropClicked(backdrop) {
+  sendVoteBackdropCommand(backdrop);
+  setBackdropOptions([]);
+}
+
+function setUndoStickerEnabled(enabled) {
+  document.getElementById("undo-sticker").style.display = enabled ? "block" : "none";
+}
+
+function undoStickerClicked() {
+  sendUndoStickerCommand();
+  setUndoStickerEnabled(false);
+}
+
+function setGuessEnabled(enabled) {
+  const input = document.getElementById("guess-input");
+  input.style.display = enabled ? "block" : "none";
+  if (!enabled) {
+    input.value = "";
   }
 }
 
+function guessKeyDown(e) {
+  if (e.key != "Enter") {
+    return;
+  }
+  const input = document.getElementById("guess-input");
+  const guess = input.value.trim();
+  if (guess != "") {
+    sendGuessCommand(guess);
+  }
+  input.value = "";
+}
+
 function setVoteOptions(voteOptions) {
   let stickerMode =( currentView == GameView.artstudioSticker);
-  let imagePath = "img/";
-  if (stickerMode) {
-    imagePath = "img/stickers/";
-  }
 
   let buttons = []
   for (let i = 0; i < 5; i++) {
@@ -106,9 +211,11 @@ function setVoteOptions(voteOptions) {
     if (voteOptions[i]) {
       const option = voteOptions[i];
       button.style.display = "block";
-      button.style.backgroundImage = "url('" + imagePath + option + ".png')";
 
       if(stickerMode) {
+        const info = getStickerInfo(option);
+        button.style.backgroundImage = "url('" + info.url + "')";
+        button.title = info.name;
         const self_index = i;
         button.classList.add("sticker");
         button.onclick = () => {
@@ -123,6 +230,8 @@ function setVoteOptions(voteOptions) {
         };
 
       } else {
+        button.style.backgroundImage = "url('img/" + option + ".png')";
+        button.title = "";
         button.classList.remove("sticker");
         button.onclick = () => sendVoteCommand(voteOptions[i]);
       }
@@ -136,6 +245,11 @@ function setPaintingPrompt(prompt) {
   document.getElementById("painter-prompt-text").innerText = prompt;
 }
 
+function setRatingProgress(votes, voters) {
+  const prompt = currentPainting ? currentPainting.prompt : "";
+  setPaintingPrompt(prompt + " (" + votes + "/" + voters + " rated)");
+}
+
 function setTimerSecondsLeft(secondsLeft) {
   if (secondsLeft < 0) {
     document.getElementById("timer-text").style.display = "none";
@@ -153,12 +267,19 @@ function updatePainting(graphics) {
 
 function setPainting(painting) {
   currentPainting = painting
+  partnerPaths = [];
   updatePainting(painting.graphics)
 }
 
+function updatePartnerPainting(graphics) {
+  partnerPaths = graphics.paths || [];
+  drawPainterCanvas();
+}
+
 function clearPainting() {
   currentPainting = null;
   painterPaths.splice(0, painterPaths.length);
+  partnerPaths = [];
   mx = -1000;
   my = -1000;
   drawPainterCanvas();
@@ -187,6 +308,9 @@ function onMouseDown(e) {
       stickerPreview.sticker,
       stickerPreview.x,
       stickerPreview.y,
+      stickerTransform.scale,
+      stickerTransform.rotation,
+      stickerTransform.flip,
     );
     setActiveSticker(null);
   }
@@ -198,7 +322,7 @@ function onMouseDown(e) {
 function onMouseMove(e) {
   mx = e.offsetX;
   my = e.offsetY;
-  if (e.buttons & 1 || chaosEffect == Effect.lock_pencil) {
+  if (e.buttons & 1 || Effect.lock_pencil in chaosEffects) {
     const point = { x: mx, y: my };
     if (selectedTool == TOOL_PENCIL) {
       pencilContinuePath(point);
@@ -248,6 +372,7 @@ function setInputEnabled(enabled) {
     painterCanvas.addEventListener("mouseup", onMouseUp);
     painterCanvas.addEventListener("mouseenter", onMouseEnter);
     painterCanvas.addEventListener("mouseleave", onMouseLeave);
+    painterCanvas.addEventListener("wheel", onStickerWheel);
   } else {
     painterCanvas.classList.remove("input-enabled");
     painterCanvas.removeEventListener("mousedown", onMouseDown);
@@ -255,28 +380,52 @@ function setInputEnabled(enabled) {
     painterCanvas.removeEventListener("mouseup", onMouseUp);
     painterCanvas.removeEventListener("mouseenter", onMouseEnter);
     painterCanvas.removeEventListener("mouseleave", onMouseLeave);
+    painterCanvas.removeEventListener("wheel", onStickerWheel);
   }
 }
 
 let stickerCache = {}
 
+// all stickers the server knows about, by id
+let stickerCatalog = {}
+
+function setStickerPacks(packs) {
+  stickerCatalog = {};
+  for (const pack of packs) {
+    for (const sticker of pack.stickers) {
+      stickerCatalog[sticker.id] = sticker;
+    }
+  }
+}
+
+// built-in stickers are known even before the server sent the packs
+function getStickerInfo(id) {
+  return stickerCatalog[id] || {
+    id: id,
+    name: id,
+    url: "img/stickers/" + id + ".png",
+    scale: 0.7,
+  };
+}
+
 function getStickerImage(name) 
 {
   let cached = stickerCache[name]
   if (cached) {
     return cached;
   }
+  const info = getStickerInfo(name);
   let sticker = {
     img: new Image(),
     ready: false,
   }
   sticker.img.onload = function() {
     sticker.ready = true;
-    sticker.width = sticker.img.width * 0.7
-    sticker.height = sticker.img.height * 0.7
+    sticker.width = sticker.img.width * info.scale
+    sticker.height = sticker.img.height * info.scale
     drawPainterCanvas();
   };
-  sticker.img.src = "img/stickers/" + name + ".png";
+  sticker.img.src = info.url;
   stickerCache[name] = sticker;
   return sticker;
 }
@@ -284,7 +433,7 @@ function getStickerImage(name)
 function drawPainterCanvas() {
   const painterCanvas = document.getElementById("painter-canvas");
   const ctx = painterCanvas.getContext("2d");
-  drawPainting(painterCanvas, painterPaths, selectedBackgroundName);
+  drawPainting(painterCanvas, partnerPaths.concat(painterPaths), selectedBackgroundName);
 
   // preview tool
   if (selectedTool == TOOL_PENCIL) {
@@ -301,78 +450,75 @@ function drawPainterCanvas() {
   }
 
   // chaos effect
-  if (chaosEffect == Effect.flashlight) {
+  if (Effect.flashlight in chaosEffects) {
     console.log("flashlight");
     ctx.beginPath();
     ctx.rect(0, 0, painterCanvas.width, painterCanvas.height);
-    ctx.arc(mx, my, 50, 2 * Math.PI, 0);
+    ctx.arc(mx, my, chaosEffects[Effect.flashlight].radius || 50, 2 * Math.PI, 0);
     ctx.fillStyle = "#000";
     ctx.fill("evenodd");
   }
 
   if (currentPainting && currentPainting.stickers) {
-    for(const meta_sticker of currentPainting.stickers) {
-      const sticker = getStickerImage(meta_sticker.id)
-      if(sticker.ready) {
-        ctx.drawImage(
-          sticker.img,
-          meta_sticker.x - sticker.width / 2,
-          meta_sticker.y - sticker.height / 2,
-          sticker.width,
-          sticker.height,
-        )
-      }
+    // stickers with a higher z are drawn on top
+    const stickers = [...currentPainting.stickers].sort((a, b) => a.z - b.z);
+    for(const meta_sticker of stickers) {
+      drawSticker(ctx, meta_sticker.id, meta_sticker);
     }
   }
 
   if (stickerPreview) {
-    let sticker = getStickerImage(stickerPreview.sticker);
-    if(sticker.ready) {
-      ctx.drawImage(
-        sticker.img,
-        stickerPreview.x - sticker.width / 2,
-        stickerPreview.y - sticker.height / 2,
-        sticker.width,
-        sticker.height,
-      )
-    }
+    drawSticker(ctx, stickerPreview.sticker, {
+      x: stickerPreview.x,
+      y: stickerPreview.y,
+      ...stickerTransform,
+    });
   }
   
 }
 
+function drawSticker(ctx, id, placement) {
+  const sticker = getStickerImage(id);
+  if(!sticker.ready) {
+    return;
+  }
+  ctx.save();
+  ctx.translate(placement.x, placement.y);
+  ctx.rotate((placement.rotation || 0) * Math.PI / 180);
+  ctx.scale((placement.flip ? -1 : 1) * (placement.scale || 1), placement.scale || 1);
+  ctx.drawImage(
+    sticker.img,
+    -sticker.width / 2,
+    -sticker.height / 2,
+    sticker.width,
+    sticker.height,
+  );
+  ctx.restore();
+}
+
 // CHAOS EFFECTS
 
-function activateChaosEffect(effect, duration) {
+function setChaosEffects(effects) {
   const painterCanvas = document.getElementById("painter-canvas");
-  console.log("activate chaos effect: " + effect);
-  chaosEffect = effect;
-  setTimeout(() => {
-    deactivateChaosEffect(effect);
-  }, duration);
-
-  if (chaosEffect == Effect.flip) {
-    painterCanvas.classList.add(Effect.flip);
-  } else if (chaosEffect == Effect.drunk) {
-    painterCanvas.classList.add(Effect.drunk);
-  } else if (chaosEffect == Effect.swap_tool) {
-    swapTools();
+  const previous = chaosEffects;
+
+  chaosEffects = {};
+  for (const active of effects) {
+    chaosEffects[active.effect] = active.parameters;
   }
+  console.log("active chaos effects: " + Object.keys(chaosEffects));
 
-  drawPainterCanvas();
-}
+  painterCanvas.classList.toggle(Effect.flip, Effect.flip in chaosEffects);
+  painterCanvas.classList.toggle(Effect.drunk, Effect.drunk in chaosEffects);
+  if (Effect.drunk in chaosEffects) {
+    painterCanvas.style.setProperty("--drunk-strength", chaosEffects[Effect.drunk].strength || 1);
+  }
 
-function deactivateChaosEffect(effect) {
-  const painterCanvas = document.getElementById("painter-canvas");
-  console.log("deactivate chaos effect: " + effect);
-  if (effect == Effect.flip) {
-    painterCanvas.classList.remove(Effect.flip);
-  } else if (effect == Effect.drunk) {
-    painterCanvas.classList.remove(Effect.drunk);
-  } else if (chaosEffect == Effect.swap_tool) {
+  // the tools are swapped back when the effect ends
+  if ((Effect.swap_tool in previous) != (Effect.swap_tool in chaosEffects)) {
     swapTools();
   }
 
-  chaosEffect = null;
   drawPainterCanvas();
 }
 
@@ -0,0 +1,8 @@
+// Package frontend provides the browser game so it can be served by the
+// backend without deploying the files separately.
+package frontend
+
+import "embed"
+
+//go:embed index.html *.js *.css img fonts
+var Files embed.FS
@@ -2,15 +2,24 @@ function initGallery()
 {
 }
 
+const GALLERY_SIZE = 4;
+
 function setGalleryCanvases(results)
 {
-    for (let i = 0; i < results.length; i++)
+    // There may be more paintings than frames, so the best ones are shown
+    const shown = results.slice().sort((a, b) => b.score - a.score).slice(0, GALLERY_SIZE);
+
+    for (let i = 0; i < GALLERY_SIZE; i++)
     {
         let galCanvas = document.getElementById("gallery" + (i+1));
-        drawPainting(galCanvas, results[i].graphics.paths, results[i].backdrop);
-        drawFinalPoints(galCanvas, results[i].score);
+        galCanvas.style.display = shown[i] ? "" : "none";
+        if (!shown[i])
+            continue;
+
+        drawPainting(galCanvas, shown[i].graphics.paths, shown[i].backdrop);
+        drawFinalPoints(galCanvas, shown[i].score);
 
-        if (results[i].winner)
+        if (shown[i].winner)
             drawWinnerBadge(galCanvas);
     }
 }
@@ -41,6 +50,26 @@ function drawWinnerBadge(canvas)
     badge.src = "img/winner_badge.png";
 }
 
+let leaderboard = [];
+
+function setLeaderboard(entries, teams)
+{
+    leaderboard = entries;
+
+    if (teams.length > 0) {
+        const best = teams.filter(team => team.rank == 1);
+        const names = best.map(team => team.members.map(member => member.name).join(" & ")).join(", ");
+        showPopUp("Team " + names + " wins with " + best[0].total.toFixed(1) + " points!", 4000);
+        return;
+    }
+
+    const best = entries.filter(entry => entry.rank == 1);
+    if (best.length > 0) {
+        const names = best.map(entry => entry.player.name).join(" & ");
+        showPopUp(names + " leads with " + best[0].total.toFixed(1) + " points!", 4000);
+    }
+}
+
 function backToLobby() {
     sendUserCommand(UserAction.leaveGallery);
 }
\ No newline at end of file
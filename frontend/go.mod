@@ -0,0 +1,3 @@
+module random-projects.net/crayos-frontend
+
+go 1.18
@@ -3,16 +3,26 @@ const Open = 1;
 
 let socket;
 let sessionID = NoSession;
+let joinSecret = "";
+let inviteCode = "";
+let isHost = false;
+let sessionSettings = null;
+let promptPacks = [];
+let stickerPacks = [];
+let backdropPacks = [];
+let submittedPrompts = [];
 
 let serverSideDisconnect = false;
 
-let players = ["", "", "", ""];
+let players = []; // PlayerInfo of all players in join order
 let localPlayer = "nickname";
+let localPlayerId = ""; // id of the local player in the PlayerInfo
 let localIsReady = false;
 
 let currentView = "connecting";
 
 const backgrounds = [];
+let backdropCatalog = {}; // all backdrops the server knows about, by id
 let selectedBackgroundName = "";
 
 function init() {
@@ -25,6 +35,8 @@ function init() {
 
     preloadAllStickers();
 
+    initReactions();
+
     initSocket();
 
     const resize = (event) => {
@@ -44,31 +56,89 @@ function initSocket() {
 
   console.log(window.location.protocol, wsproto);
 
-  let socketUrl = wsproto + "//" + window.location.hostname + "/ws";
+  let serverHost = window.location.host;
   {
     let urlParams = new URLSearchParams(window.location.search);
     if (urlParams.has("local")) {
-      socketUrl = wsproto + "//localhost:8080/ws";
+      serverHost = "localhost:8080";
     } else if (urlParams.has("host")) {
-      socketUrl = wsproto + "//192.168.37.247:8090/ws";
+      serverHost = "192.168.37.247:8090";
     }
   }
+  let socketUrl = wsproto + "//" + serverHost + "/ws";
   console.log("socket url: " + socketUrl);
 
   document.getElementById("connecting").style.display = "flow";
   socket = new WebSocket(socketUrl);
+  attachSocketHandlers(socket);
 
+  let opened = false;
+  socket.addEventListener("open", () => opened = true);
   socket.onerror = function (event) {
     console.log("WebSocket error: ", event);
+    if (!opened) {
+      // Some networks block websockets, fall back to server-sent events:
+      console.log("falling back to event stream");
+      socket = createEventStreamSocket(window.location.protocol + "//" + serverHost);
+      attachSocketHandlers(socket);
+    }
   };
-  socket.onopen = function (event) {
+
+  setInterval(timeoutCheck, 3000);
+}
+
+function attachSocketHandlers(target) {
+  target.onopen = function (event) {
     setView("title");
   };
-  socket.onmessage = function (event) {
+  target.onmessage = function (event) {
     onSocketReceive(event);
   };
+}
 
-  setInterval(timeoutCheck, 3000);
+// Receives events via server-sent events and posts commands, but looks
+// like a WebSocket to the rest of the code.
+function createEventStreamSocket(serverUrl) {
+  const stream = new EventSource(serverUrl + "/sse");
+  let pending = Promise.resolve();
+
+  const fallback = {
+    readyState: 0,
+    token: null,
+    onopen: null,
+    onmessage: null,
+    send(data) {
+      // commands must arrive in order
+      pending = pending.then(() => fetch(serverUrl + "/sse/command?token=" + fallback.token, {
+        method: "POST",
+        body: data,
+      })).catch((error) => console.log("failed to send command: ", error));
+    },
+    close() {
+      stream.close();
+      fallback.readyState = 3;
+    },
+  };
+
+  stream.addEventListener("token", (event) => {
+    fallback.token = event.data;
+    fallback.readyState = Open;
+    if (fallback.onopen) {
+      fallback.onopen(event);
+    }
+  });
+  stream.onmessage = (event) => {
+    if (fallback.onmessage) {
+      fallback.onmessage(event);
+    }
+  };
+  stream.onerror = (event) => {
+    // A reconnect would create a new player, so give up like a websocket would.
+    console.log("EventSource error: ", event);
+    fallback.close();
+  };
+
+  return fallback;
 }
 
 function timeoutCheck() {
@@ -106,6 +176,49 @@ function showSection(id) {
   document.getElementById(id).style.display = "flow";
 }
 
+const REACTION_EMOJIS = {
+  [Reaction.laugh]: "\u{1F602}",
+  [Reaction.love]: "\u{1F60D}",
+  [Reaction.shock]: "\u{1F631}",
+  [Reaction.applause]: "\u{1F44F}",
+  [Reaction.fire]: "\u{1F525}",
+  [Reaction.poop]: "\u{1F4A9}",
+};
+
+// Players can react to the paintings in the showcase and the gallery
+function initReactions() {
+  const bar = document.getElementById("reactions");
+  for (const [reaction, emoji] of Object.entries(REACTION_EMOJIS)) {
+    let button = document.createElement("button");
+    button.textContent = emoji;
+    button.onclick = () => sendReactCommand(reaction);
+    bar.appendChild(button);
+  }
+}
+
+function setReactionsEnabled(enabled) {
+  let bar = document.getElementById("reactions");
+  bar.style.display = enabled ? "flex" : "none";
+  bar.classList.toggle("gallery", currentView == GameView.gallery);
+}
+
+// Number of chat messages that stay visible
+const CHAT_LOG_LENGTH = 4;
+
+function addChatMessage(sender, message) {
+  let log = document.getElementById("chat-log");
+  let line = document.createElement("div");
+  line.textContent = sender + ": " + message;
+  log.appendChild(line);
+  while (log.children.length > CHAT_LOG_LENGTH) {
+    log.removeChild(log.firstChild);
+  }
+}
+
+function clearChatLog() {
+  document.getElementById("chat-log").replaceChildren();
+}
+
 let global_popup_timeout 
 
 function showPopUp(message, duration) {
@@ -122,10 +235,39 @@ function showPopUp(message, duration) {
 }
 
 
+// Players can chat in the lobby and the art studio
+function isChatView(view) {
+  switch (view) {
+    case GameView.lobby:
+    case GameView.promptselection:
+    case GameView.artstudioGeneric:
+    case GameView.artstudioActive:
+    case GameView.artstudioSticker:
+      return true;
+  }
+  return false;
+}
+
+function chatKeyDown(e) {
+  if (e.key != "Enter") {
+    return;
+  }
+  const input = document.getElementById("chat-input");
+  const message = input.value.trim();
+  if (message != "") {
+    sendChatCommand(message);
+  }
+  input.value = "";
+}
+
 function setView(newView) {
   hideSection(currentView);
   currentView = newView;
   showSection(newView);
+  document.getElementById("chat-input").style.display = isChatView(newView) ? "block" : "none";
+  let chatLog = document.getElementById("chat-log");
+  chatLog.style.display = isChatView(newView) ? "block" : "none";
+  chatLog.classList.toggle("lobby", newView == GameView.lobby);
 
   if (newView == GameView.gallery) {
       initGallery();
@@ -143,11 +285,55 @@ function onSocketReceive(event) {
   switch (data.type) {
     case EventId.EnterSession:
       sessionID = data.sessionId;
+      joinSecret = data.joinSecret;
+      localPlayerId = data.playerId;
+      inviteCode = "";
+      isHost = false; // until the players are known
+      clearChatLog();
+      break;
+    case EventId.PublicSessions:
+      setPublicSessions(data.sessions);
       break;
     case EventId.JoinSessionFailed:
         setView("server_error");
       document.getElementById("serverErrorText").textContent = data.reason;
       break;
+    case EventId.SettingsChanged:
+      sessionSettings = data.settings;
+      localIsReady = false; // everyone has to agree to the new settings
+      updateSettings();
+      updateLobby();
+      break;
+    case EventId.PromptPacks:
+      promptPacks = data.packs;
+      updateSettings();
+      break;
+    case EventId.StickerPacks:
+      stickerPacks = data.packs;
+      setStickerPacks(data.packs);
+      updateSettings();
+      break;
+    case EventId.BackdropPacks:
+      backdropPacks = data.packs;
+      setBackdropPacks(data.packs);
+      updateSettings();
+      break;
+    case EventId.SubmittedPrompts:
+      submittedPrompts = data.prompts;
+      break;
+    case EventId.RatingProgress:
+      setRatingProgress(data.votes, data.voters);
+      break;
+    case EventId.Leaderboard:
+      setLeaderboard(data.entries, data.teams);
+      break;
+    case EventId.Scoreboard:
+      setScoreboard(data);
+      break;
+    case EventId.InviteCreated:
+      inviteCode = data.invite;
+      updateLobby();
+      break;
     case EventId.Kicked:
       alert(data.reason);
       break;
@@ -176,6 +362,12 @@ function onSocketReceive(event) {
         setVoteOptions(data.voteOptions);
       }
 
+      setSkipTurnEnabled(data.canSkip);
+      setUndoStickerEnabled(data.canUndoSticker);
+      setGuessEnabled(data.canGuess);
+      setReactionsEnabled(data.canReact);
+      setBackdropOptions(data.backdropOptions);
+
       if (data.view == GameView.artstudioActive) {
         setPaintingToolsEnabled(true);
       } else {
@@ -193,28 +385,22 @@ function onSocketReceive(event) {
     case EventId.TimerChanged:
       setTimerSecondsLeft(data.secondsLeft);
       break;
-    case EventId.ChangeToolModifier:
-      if (data.modifier == "") {
-        // special handling: deactivate current effect
-        deactivateChaosEffect(chaosEffect);
-      } else {
-        activateChaosEffect(data.modifier, data.duration);
-      }
+    case EventId.ActiveEffectsChanged:
+      setChaosEffects(data.effects);
       break;
     case EventId.PaintingChanged:
-      updatePainting(data.graphics);
-      break;
-    case EventId.PlayersChanged:
-      for (let i = 0; i < 4; i++) {
-        if (i < data.players.length)
-            players[i] = data.players[i];
-        else
-            players[i] = "";
+      if (currentView == GameView.artstudioActive) {
+        // Only sent while painting if someone paints along with us
+        updatePartnerPainting(data.graphics);
+      } else {
+        updatePainting(data.graphics);
       }
-      updateLobby();
       break;
+    case EventId.PlayersChanged:
     case EventId.PlayerReadyChanged:
-      updateLobby(data.players);
+      players = data.players;
+      updateHost();
+      updateLobby();
       break;
 
     case EventId.DebugMessage:
@@ -227,6 +413,17 @@ function onSocketReceive(event) {
       showPopUp(data.message, data.duration);
       break;
 
+    case EventId.ChatMessage:
+      addChatMessage(data.sender.name, data.message);
+      if (!isChatView(currentView)) {
+        showPopUp(data.sender.name + ": " + data.message, 3000);
+      }
+      break;
+
+    case EventId.Reaction:
+      showPopUp(data.sender.name + " " + REACTION_EMOJIS[data.reaction]);
+      break;
+
     default:
       throw "unhandled message: " + JSON.stringify(data);
   }
@@ -241,6 +438,34 @@ function loadBackgrounds() {
   backgrounds["desert"] = document.getElementById("background-desert");
 }
 
+function setBackdropPacks(packs) {
+  backdropCatalog = {};
+  for (const pack of packs) {
+    for (const backdrop of pack.backdrops) {
+      backdropCatalog[backdrop.id] = backdrop;
+      getBackdropImage(backdrop.id); // preload
+    }
+  }
+}
+
+function getBackdropInfo(id) {
+  return backdropCatalog[id] || {
+    id: id,
+    name: id,
+    url: "img/" + id + ".png",
+  };
+}
+
+function getBackdropImage(id) {
+  if (!backgrounds[id]) {
+    const img = new Image();
+    img.onload = () => drawPainterCanvas();
+    img.src = getBackdropInfo(id).url;
+    backgrounds[id] = img;
+  }
+  return backgrounds[id];
+}
+
 function setBackground(backgroundName) {
   selectedBackgroundName = backgroundName;
   drawPainterCanvas();
@@ -248,8 +473,9 @@ function setBackground(backgroundName) {
 
 function drawPainting(canvas, paths, backgroundName) {
   const ctx = canvas.getContext("2d");
-  if (backgroundName != "") {
-    ctx.drawImage(backgrounds[backgroundName], 0, 0, canvas.width, canvas.height);
+  const backdrop = backgroundName != "" ? getBackdropImage(backgroundName) : null;
+  if (backdrop && backdrop.complete && backdrop.naturalWidth > 0) {
+    ctx.drawImage(backdrop, 0, 0, canvas.width, canvas.height);
   } else {
     ctx.clearRect(0, 0, canvas.width, canvas.height);
   }
@@ -284,6 +510,19 @@ function drawPainting(canvas, paths, backgroundName) {
   }
 }
 
+// The host role passes on when the host leaves
+function updateHost() {
+  const wasHost = isHost;
+  isHost = players.some(player => player.id == localPlayerId && player.host);
+  if (isHost != wasHost) {
+    updateSettings();
+  }
+  if (isHost && !wasHost) {
+    // Only fetch the invite, the password is set in the lobby
+    sendCreateInviteCommand("", false);
+  }
+}
+
 // Temporarily changes the text of a button for time t
 function tempChangeBtnText(btnId, tempText, t) {
     let btn = document.getElementById(btnId);
@@ -1,25 +1,34 @@
 let inviteLink;
+let scoreboard = { matches: 0, entries: [] };
 
-function updateLobby(readyMap = undefined) {
+function updateLobby() {
     // Update Nicknames and ready status
-    for (let i = 0; i < players.length; i++) {
-        let playerInfo = document.getElementById("player" + (i+1));
-        playerInfo.value = players[i];
-        if (readyMap != undefined) {
-            if (readyMap[players[i]] == true) {
-                playerInfo.classList.add("ready");
-                playerInfo.style.display = "block";
-            }
-            else {
-                if (players[i] == "") {
-                    playerInfo.style.display = "none";
-                } else {
-                    playerInfo.classList.remove("ready");
-                    playerInfo.style.display = "block";
-                }
+    let playerList = document.getElementById("playerList");
+    playerList.replaceChildren();
+    playerList.classList.toggle("compact", players.length > 4);
+    for (const player of players) {
+        let playerInfo = document.createElement("input");
+        playerInfo.type = "text";
+        playerInfo.disabled = true;
+        playerInfo.className = "genericUi playerName";
+        playerInfo.value = player.name;
+        if (player.ready) {
+            playerInfo.classList.add("ready");
+        }
+        if (player.bot) {
+            playerInfo.value = "\u{1F916} " + player.name;
+            if (isHost) {
+                // The host sends bots away by clicking them
+                playerInfo.disabled = false;
+                playerInfo.readOnly = true;
+                playerInfo.title = "Remove bot";
+                playerInfo.onclick = () => sendRemoveBotCommand(player.id);
             }
         }
+        playerList.appendChild(playerInfo);
     }
+    document.getElementById("addBot").style.display = isHost ? "block" : "none";
+    document.getElementById("passwordWrapper").style.display = isHost ? "flex" : "none";
 
     // Local ready button
     if (localIsReady == true) {
@@ -31,10 +40,44 @@ function updateLobby(readyMap = undefined) {
 
     // Show invite link
     let hostUrl = location.protocol + '//' + location.host + location.pathname;
-    inviteLink = hostUrl + "?session=" + sessionID;
+    if (inviteCode) {
+        inviteLink = hostUrl + "?invite=" + inviteCode;
+    } else {
+        inviteLink = hostUrl + "?session=" + sessionID;
+        if (joinSecret) {
+            inviteLink += "&secret=" + joinSecret;
+        }
+    }
 
     // Show ID
     document.getElementById("copyId").value = "ID: " + sessionID;
+
+    updateScoreboard();
+}
+
+function setScoreboard(evt) {
+    scoreboard = evt;
+    updateScoreboard();
+}
+
+function updateScoreboard() {
+    // The rules are shown until the first match is over
+    const played = scoreboard.matches > 0;
+    document.getElementById("lobbyRules").style.display = (played || showSettings) ? "none" : "block";
+    document.getElementById("scoreboard").style.display = (played && !showSettings) ? "block" : "none";
+    document.getElementById("resetScoreboard").style.display = (played && isHost && !showSettings) ? "block" : "none";
+
+    let list = document.getElementById("scoreboard");
+    list.replaceChildren();
+    for (const entry of scoreboard.entries) {
+        let item = document.createElement("li");
+        item.textContent = entry.player.name + ": " + entry.wins + " wins, "
+            + entry.stars.toFixed(1) + " stars";
+        if (entry.bestPainting) {
+            item.textContent += " (best: " + entry.bestPainting.prompt + ")";
+        }
+        list.appendChild(item);
+    }
 }
 
 function readyClicked() {
@@ -49,6 +92,20 @@ function readyClicked() {
     updateLobby();
 }
 
+function btnAddBot() {
+    sendAddBotCommand();
+}
+
+function btnResetScoreboard() {
+    sendResetScoreboardCommand();
+}
+
+// The new password is part of the invite links created from now on
+function btnSetPassword() {
+    sendCreateInviteCommand(document.getElementById("lobbyPassword").value, true);
+    tempChangeBtnText("setPassword", "Password Set!", 2000);
+}
+
 function btnCopyInvite() {
     navigator.clipboard.writeText(inviteLink);
     tempChangeBtnText("joinLink", "Link Copied!", 2000);
@@ -0,0 +1,159 @@
+// Settings of the session in the lobby. Only the host may change them,
+// everyone else sees what will be played.
+
+let showSettings = false;
+
+const GAME_MODE_NAMES = {
+  [GameMode.classic]: "Classic",
+  [GameMode.guessing]: "Guess the prompt",
+};
+
+const TURN_ORDER_NAMES = {
+  [TurnOrder.rotation]: "Everyone paints",
+  [TurnOrder.random]: "Random painters",
+};
+
+const EFFECT_NAMES = {
+  [Effect.flashlight]: "Flashlight",
+  [Effect.drunk]: "Drunk",
+  [Effect.flip]: "Flip",
+  [Effect.swap_tool]: "Swap tool",
+  [Effect.lock_pencil]: "Lock pencil",
+};
+
+// Same limits as the server checks
+const SETTINGS_NUMBERS = [
+  { field: "maxPlayers", label: "Max. players", min: 2, max: 16, step: 1 },
+  { field: "paintingTime", label: "Painting time (s)", min: 10, max: 300, step: 10 },
+  { field: "paintTurns", label: "Turns per player", min: 1, max: 5, step: 1, order: TurnOrder.rotation },
+  { field: "rounds", label: "Rounds", min: 1, max: 20, step: 1, order: TurnOrder.random },
+  { field: "stickers", label: "Stickers per troll", min: 1, max: 5, step: 1 },
+];
+
+function btnToggleSettings() {
+  showSettings = !showSettings;
+  updateSettings();
+  updateScoreboard();
+}
+
+// Sends the settings with one field changed. The form shows the current
+// settings until the server accepts the new ones.
+function changeSetting(field, value) {
+  let settings = Object.assign({}, sessionSettings);
+  settings[field] = value;
+  updateSettings();
+  sendChangeSettingsCommand(settings);
+}
+
+function toggleSettingsItem(field, id, selected) {
+  let ids = sessionSettings[field].filter(item => item != id);
+  if (selected) {
+    ids.push(id);
+  }
+  changeSetting(field, ids);
+}
+
+function addSettingsRow(panel, text, control) {
+  let label = document.createElement("span");
+  label.className = "settingsLabel";
+  label.textContent = text;
+  panel.appendChild(label);
+  panel.appendChild(control);
+}
+
+function createSettingsNumber(number) {
+  let input = document.createElement("input");
+  input.type = "number";
+  input.className = "genericUi";
+  input.min = number.min;
+  input.max = number.max;
+  input.step = number.step;
+  input.value = sessionSettings[number.field];
+  input.disabled = !isHost;
+  input.onchange = () => {
+    const value = parseInt(input.value);
+    if (isNaN(value)) {
+      updateSettings();
+    } else {
+      changeSetting(number.field, value);
+    }
+  };
+  return input;
+}
+
+function createSettingsSelect(field, names) {
+  let select = document.createElement("select");
+  select.className = "genericUi";
+  for (const [value, name] of Object.entries(names)) {
+    let option = document.createElement("option");
+    option.value = value;
+    option.textContent = name;
+    select.appendChild(option);
+  }
+  select.value = sessionSettings[field];
+  select.disabled = !isHost;
+  select.onchange = () => changeSetting(field, select.value);
+  return select;
+}
+
+function createSettingsCheckbox(text, checked, onchange) {
+  let label = document.createElement("label");
+  let checkbox = document.createElement("input");
+  checkbox.type = "checkbox";
+  checkbox.checked = checked;
+  checkbox.disabled = !isHost;
+  checkbox.onchange = () => onchange(checkbox.checked);
+  label.appendChild(checkbox);
+  label.appendChild(document.createTextNode(" " + text));
+  return label;
+}
+
+// Checkboxes for all items of a list setting, like the prompt packs
+function createSettingsList(field, items) {
+  let list = document.createElement("div");
+  list.className = "settingsList";
+  for (const item of items) {
+    list.appendChild(createSettingsCheckbox(item.name, sessionSettings[field].includes(item.id),
+      (checked) => toggleSettingsItem(field, item.id, checked)));
+  }
+  return list;
+}
+
+function updateSettings() {
+  const panel = document.getElementById("settings");
+  panel.style.display = showSettings ? "grid" : "none";
+  document.getElementById("settingsButton").textContent = showSettings ? "Rules" : "Settings";
+  if (!showSettings || !sessionSettings) {
+    return;
+  }
+
+  panel.replaceChildren();
+  addSettingsRow(panel, "Mode", createSettingsSelect("mode", GAME_MODE_NAMES));
+  addSettingsRow(panel, "Turns", createSettingsSelect("turnOrder", TURN_ORDER_NAMES));
+  for (const number of SETTINGS_NUMBERS) {
+    if (!number.order || number.order == sessionSettings.turnOrder) {
+      addSettingsRow(panel, number.label, createSettingsNumber(number));
+    }
+  }
+  addSettingsRow(panel, "Teams", createSettingsCheckbox("Paint in pairs",
+    sessionSettings.teams, (checked) => changeSetting("teams", checked)));
+  addSettingsRow(panel, "Backdrop", createSettingsCheckbox("Trolls vote for the backdrop",
+    sessionSettings.backdropVote, (checked) => changeSetting("backdropVote", checked)));
+
+  addSettingsRow(panel, "Prompts", createSettingsList("promptPacks", promptPacks.map(pack => ({
+    id: pack.id,
+    name: pack.name + " (" + pack.language + ", " + pack.rating + ", " + pack.prompts + ")",
+  }))));
+  addSettingsRow(panel, "Stickers", createSettingsList("stickerPacks", stickerPacks.map(pack => ({
+    id: pack.id,
+    name: pack.name + " (" + pack.stickers.length + ")",
+  }))));
+  addSettingsRow(panel, "Backdrops", createSettingsList("backdropPacks", backdropPacks.map(pack => ({
+    id: pack.id,
+    name: pack.name + " (" + pack.backdrops.length + ")",
+  }))));
+  addSettingsRow(panel, "Effects", createSettingsList("effects", Object.entries(EFFECT_NAMES).map(([id, name]) => ({
+    id: id,
+    name: name,
+  }))));
+}
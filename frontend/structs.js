@@ -2,9 +2,23 @@ const CommandId = {
     CreateSession : 'create-session-command',
     JoinSession : 'join-session-command',
     LeaveSession : 'leave-session-command',
+    CreateInvite : 'create-invite-command',
+    ListPublicSessions : 'list-public-sessions-command',
+    QuickMatch : 'quick-match-command',
+    ChangeSettings : 'change-settings-command',
+    SubmitPrompt : 'submit-prompt-command',
+    AddBot : 'add-bot-command',
+    RemoveBot : 'remove-bot-command',
+    ResetScoreboard : 'reset-scoreboard-command',
     User : 'user-command',
     Vote : 'vote-command',
+    Chat : 'chat-command',
+    React : 'react-command',
+    Guess : 'guess-command',
+    VoteSticker : 'vote-sticker-command',
     PlaceSticker : 'place-sticker-command',
+    VoteBackdrop : 'vote-backdrop-command',
+    UndoSticker : 'undo-sticker-command',
     SetPainting : 'set-painting-command',
 };
 
@@ -12,12 +26,24 @@ const EventId = {
     EnterSession : 'enter-session-event',
     JoinSessionFailed : 'join-session-failed-event',
     Kicked : 'kicked-event',
+    SettingsChanged : 'settings-changed-event',
+    PromptPacks : 'prompt-packs-event',
+    StickerPacks : 'sticker-packs-event',
+    BackdropPacks : 'backdrop-packs-event',
+    SubmittedPrompts : 'submitted-prompts-event',
+    InviteCreated : 'invite-created-event',
+    PublicSessions : 'public-sessions-event',
     ChangeGameView : 'change-game-view-event',
     TimerChanged : 'timer-changed-event',
-    ChangeToolModifier : 'change-tool-modifier-event',
+    ActiveEffectsChanged : 'active-effects-changed-event',
     PaintingChanged : 'painting-changed-event',
     PlayersChanged : 'players-changed-event',
     PlayerReadyChanged : 'player-ready-changed-event',
+    ChatMessage : 'chat-message-event',
+    Reaction : 'reaction-event',
+    RatingProgress : 'rating-progress-event',
+    Leaderboard : 'leaderboard-event',
+    Scoreboard : 'scoreboard-event',
     PopUp : 'pop-up-event',
     DebugMessage : 'debug-message-event',
 };
@@ -48,6 +74,17 @@ const UserAction = {
     setReady : 'set-ready',
     setNotReady : 'set-not-ready',
     leaveGallery : 'leave-gallery',
+    skipTurn : 'skip-turn',
+};
+
+// Enum:
+const Reaction = {
+    laugh : 'laugh',
+    love : 'love',
+    shock : 'shock',
+    applause : 'applause',
+    fire : 'fire',
+    poop : 'poop',
 };
 
 // Enum:
@@ -59,22 +96,47 @@ const Backdrop = {
     desert : 'desert',
 };
 
+// Enum:
+const GameMode = {
+    classic : 'classic',
+    guessing : 'guessing',
+};
+
+// Enum:
+const TurnOrder = {
+    rotation : 'rotation',
+    random : 'random',
+};
+
+// Enum:
+const ContentRating = {
+    everyone : 'everyone',
+    teen : 'teen',
+    mature : 'mature',
+};
+
 // Command:
-function sendCreateSessionCommand(nickName)
+function sendCreateSessionCommand(nickName, requireSecret, public, password)
 {
     socket.send(JSON.stringify({
         type : CommandId.CreateSession,
         nickName : nickName, // str
+        requireSecret : requireSecret, // bool
+        public : public, // bool
+        password : password, // str
     }));
 }
 
 // Command:
-function sendJoinSessionCommand(nickName, sessionId)
+function sendJoinSessionCommand(nickName, sessionId, joinSecret, password, invite)
 {
     socket.send(JSON.stringify({
         type : CommandId.JoinSession,
         nickName : nickName, // str
         sessionId : sessionId, // str
+        joinSecret : joinSecret, // str
+        password : password, // str
+        invite : invite, // str
     }));
 }
 
@@ -86,6 +148,76 @@ function sendLeaveSessionCommand()
     }));
 }
 
+// Command:
+function sendCreateInviteCommand(password, setPassword)
+{
+    socket.send(JSON.stringify({
+        type : CommandId.CreateInvite,
+        password : password, // str
+        setPassword : setPassword, // bool
+    }));
+}
+
+// Command:
+function sendListPublicSessionsCommand()
+{
+    socket.send(JSON.stringify({
+        type : CommandId.ListPublicSessions,
+    }));
+}
+
+// Command:
+function sendQuickMatchCommand(nickName)
+{
+    socket.send(JSON.stringify({
+        type : CommandId.QuickMatch,
+        nickName : nickName, // str
+    }));
+}
+
+// Command:
+function sendChangeSettingsCommand(settings)
+{
+    socket.send(JSON.stringify({
+        type : CommandId.ChangeSettings,
+        settings : settings, // SessionSettings
+    }));
+}
+
+// Command:
+function sendSubmitPromptCommand(prompt)
+{
+    socket.send(JSON.stringify({
+        type : CommandId.SubmitPrompt,
+        prompt : prompt, // str
+    }));
+}
+
+// Command:
+function sendAddBotCommand()
+{
+    socket.send(JSON.stringify({
+        type : CommandId.AddBot,
+    }));
+}
+
+// Command:
+function sendRemoveBotCommand(player)
+{
+    socket.send(JSON.stringify({
+        type : CommandId.RemoveBot,
+        player : player, // str
+    }));
+}
+
+// Command:
+function sendResetScoreboardCommand()
+{
+    socket.send(JSON.stringify({
+        type : CommandId.ResetScoreboard,
+    }));
+}
+
 // Command:
 function sendUserCommand(action)
 {
@@ -105,13 +237,69 @@ function sendVoteCommand(option)
 }
 
 // Command:
-function sendPlaceStickerCommand(sticker, x, y)
+function sendChatCommand(message)
+{
+    socket.send(JSON.stringify({
+        type : CommandId.Chat,
+        message : message, // str
+    }));
+}
+
+// Command:
+function sendReactCommand(reaction)
+{
+    socket.send(JSON.stringify({
+        type : CommandId.React,
+        reaction : reaction, // Reaction
+    }));
+}
+
+// Command:
+function sendGuessCommand(guess)
+{
+    socket.send(JSON.stringify({
+        type : CommandId.Guess,
+        guess : guess, // str
+    }));
+}
+
+// Command:
+function sendVoteStickerCommand(sticker)
+{
+    socket.send(JSON.stringify({
+        type : CommandId.VoteSticker,
+        sticker : sticker, // int
+    }));
+}
+
+// Command:
+function sendPlaceStickerCommand(sticker, x, y, scale, rotation, flip)
 {
     socket.send(JSON.stringify({
         type : CommandId.PlaceSticker,
         sticker : sticker, // str
         x : x, // float
         y : y, // float
+        scale : scale, // float
+        rotation : rotation, // float
+        flip : flip, // bool
+    }));
+}
+
+// Command:
+function sendVoteBackdropCommand(backdrop)
+{
+    socket.send(JSON.stringify({
+        type : CommandId.VoteBackdrop,
+        backdrop : backdrop, // str
+    }));
+}
+
+// Command:
+function sendUndoStickerCommand()
+{
+    socket.send(JSON.stringify({
+        type : CommandId.UndoSticker,
     }));
 }
 
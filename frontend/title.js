@@ -2,18 +2,51 @@ function initTitle() {
     extractSessionId();
     document.getElementById("sessionIdInput").value = sessionID;
     document.getElementById("nicknameInput").placeholder = nick_names[Math.floor(Math.random() * nick_names.length)];
+    sendListPublicSessionsCommand();
 }
 
 function createGame() {
     getNickname()
     
-    sendCreateSessionCommand(localPlayer);
+    sendCreateSessionCommand(localPlayer, false, false, "");
 }
 
 function joinGame() {
     getNickname();
     sessionID = document.getElementById("sessionIdInput").value;
-    sendJoinSessionCommand(localPlayer, sessionID);
+    sendJoinSessionCommand(localPlayer, sessionID, joinSecret, "", inviteCode);
+}
+
+function quickMatch() {
+    getNickname();
+    sendQuickMatchCommand(localPlayer);
+}
+
+function joinPublicSession(id) {
+    getNickname();
+    sessionID = id;
+    sendJoinSessionCommand(localPlayer, sessionID, "", "", "");
+}
+
+// Shows the public lobbies, clicking one joins it
+function setPublicSessions(sessions) {
+    let list = document.getElementById("publicSessions");
+    list.replaceChildren();
+
+    let refresh = document.createElement("button");
+    refresh.className = "publicSessionsRefresh";
+    refresh.textContent = sessions.length ? "Public lobbies \u21BB" : "No public lobbies \u21BB";
+    refresh.onclick = () => sendListPublicSessionsCommand();
+    list.appendChild(refresh);
+
+    for (const info of sessions) {
+        let entry = document.createElement("button");
+        entry.className = "publicSession";
+        entry.textContent = info.hostName + " (" + info.players + "/" + info.settings.maxPlayers + ") - "
+            + GAME_MODE_NAMES[info.settings.mode];
+        entry.onclick = () => joinPublicSession(info.sessionId);
+        list.appendChild(entry);
+    }
 }
 
 function btnBackToLobby() {
@@ -29,6 +62,8 @@ function extractSessionId() {
     else {
         sessionID = "";
     }
+    joinSecret = urlParams.get("secret") || "";
+    inviteCode = urlParams.get("invite") || "";
 }
 
 // Checks if the nick is valid, otherwise defaults
@@ -40,6 +40,7 @@ GO_TYPES: dict[type,str] = {
     list[str]: "[]string",
     None | list[str]: "[]string",
     dict[str, bool]: "map[string]bool",
+    dict[str, float]: "map[string]float32",
     Graphics: "Graphics",
 }
 
@@ -104,6 +105,11 @@ class Sticker:
     id: str
     x: float 
     y: float 
+    author: str # id of the player that placed the sticker
+    scale: float # 1 is the original size of the sticker
+    rotation: float # clockwise, in degrees from 0 to 360
+    flip: bool # mirrored horizontally
+    z: int # stickers with a higher z are drawn on top
 
 @api_enum
 class GameView(Enum):
@@ -132,29 +138,155 @@ class UserAction(Enum):
 
     leaveGallery = "leave-gallery" # leave the gallery and return to the lobby
 
+    skipTurn = "skip-turn" # the painter passes their turn while the prompt is selected
+
+@api_enum
+class Reaction(Enum):
+    laugh = "laugh"
+    love = "love"
+    shock = "shock"
+    applause = "applause"
+    fire = "fire"
+    poop = "poop"
+
 @api_enum
-class Backdrop(Enum):
+class Backdrop(Enum): # the backdrops that are built into the frontend
 	arctic  = "arctic"
 	graveyard  = "graveyard"
 	pirateShip  = "pirate_ship"
 	theaterStage1  = "theater_stage1"
 	desert  = "desert"
 
+@api_enum
+class GameMode(Enum):
+    classic = "classic" # trolls select the prompt and everyone knows it
+    guessing = "guessing" # the painter selects the prompt and the trolls have to guess it
+
+@api_enum
+class TurnOrder(Enum):
+    rotation = "rotation" # every player paints SessionSettings.paintTurns times, in a new order each rotation
+    random = "random" # SessionSettings.rounds rounds with a random painter each
+
+@api_enum
+class ContentRating(Enum):
+    everyone = "everyone"
+    teen = "teen"
+    mature = "mature"
+
+@api_struct
+class PromptPackInfo:
+    id: str
+    name: str
+    language: str # language code of the prompts, e.g. "en"
+    category: str
+    rating: ContentRating
+    prompts: int # number of prompts in the pack
+
+@api_struct
+class StickerInfo:
+    id: str # used in PlaceStickerCommand and Sticker
+    name: str # display name of the sticker
+    url: str # where the image of the sticker is loaded from
+    scale: float # size of the sticker at scale 1, relative to the image
+
+@api_struct
+class StickerPackInfo:
+    id: str
+    name: str
+    category: str
+    stickers: list[StickerInfo]
+
+@api_struct
+class BackdropInfo:
+    id: str # used in Painting.backdrop
+    name: str # display name of the backdrop
+    url: str # where the image of the backdrop is loaded from
+    themes: list[str] # what the backdrop shows, e.g. "cold" or "spooky"
+    categories: list[str] # prompt pack categories the backdrop fits best
+
+@api_struct
+class BackdropPackInfo:
+    id: str
+    name: str
+    backdrops: list[BackdropInfo]
+
+@api_struct
+class SessionSettings:
+    maxPlayers: int # maximum number of players in the session
+    paintingTime: int # duration of a painting round in seconds
+    mode: GameMode
+    turnOrder: TurnOrder
+    paintTurns: int # rotation: how many times each player paints
+    rounds: int # random: number of paintings in the match
+    teams: bool # two players paint together while the others troll, needs at least 3 players
+    stickers: int # how many stickers each troll may place on a painting
+    promptPacks: list[str] # ids of the prompt packs the prompts are taken from
+    stickerPacks: list[str] # ids of the sticker packs the trolls' stickers are taken from
+    backdropPacks: list[str] # ids of the backdrop packs the backdrops are taken from
+    backdropVote: bool # the trolls vote for the backdrop while the prompt is selected
+    effects: list[Effect] # troll effects that may be offered to the trolls
+
+@api_struct
+class PublicSessionInfo:
+    sessionId: str
+    hostName: str # nickname of the host
+    players: int # number of players in the lobby
+    settings: SessionSettings
+
 @api_command
 class CreateSessionCommand:
     nickName: str
+    requireSecret: bool # players can only join with the join secret
+    public: bool # the session is listed in the lobby browser
+    password: str # players must enter this password to join, empty if none is required
 
 
 @api_command
 class JoinSessionCommand:
     nickName: str 
     sessionId: str 
+    joinSecret: str # required if the session was created with requireSecret
+    password: str # required if the session has a password
+    invite: str # code of an invite link, replaces sessionId, joinSecret and password
 
 
 @api_command
 class LeaveSessionCommand:
     pass 
 
+@api_command
+class CreateInviteCommand:
+    password: str # the new session password if setPassword is set, empty removes it
+    setPassword: bool # host only: otherwise the password is kept. Answered with an InviteCreatedEvent
+
+@api_command
+class ListPublicSessionsCommand:
+    pass # answered with a PublicSessionsEvent
+
+@api_command
+class QuickMatchCommand:
+    nickName: str # joins the fullest public lobby or creates a new one
+
+@api_command
+class ChangeSettingsCommand:
+    settings: SessionSettings # host only, in the lobby
+
+@api_command
+class SubmitPromptCommand:
+    prompt: str # lobby: adds a custom prompt that may be offered to the other players
+
+@api_command
+class AddBotCommand:
+    pass # host only, in the lobby: adds a bot player that fills an empty seat
+
+@api_command
+class RemoveBotCommand:
+    player: str # host only, in the lobby: id of the bot that should leave
+
+@api_command
+class ResetScoreboardCommand:
+    pass # host only, in the lobby: forgets the results of all previous matches
+
 @api_command
 class UserCommand:
     action: UserAction
@@ -164,11 +296,38 @@ class VoteCommand:
     option: str # user has voted for an option from ChangeGameViewEvent.voteOptions
 
 
+@api_command
+class ChatCommand:
+    message: str # sends a chat message to the other players in the session
+
+@api_command
+class ReactCommand:
+    reaction: Reaction # quick reaction, only allowed while paintings are shown
+
+@api_command
+class GuessCommand:
+    guess: str # guessing mode: what the troll thinks the painter is drawing
+
+@api_command
+class VoteStickerCommand:
+    sticker: int # showcase: index of the best sticker in Painting.stickers, can't be your own
+
 @api_command
 class PlaceStickerCommand:
-    sticker: str
-    x: float 
-    y: float  
+    sticker: str # one of the voteOptions of the sticker view
+    x: float # position on the canvas, from 0 to 1385
+    y: float # position on the canvas, from 0 to 737
+    scale: float # from 0.5 to 2
+    rotation: float # clockwise, in degrees
+    flip: bool
+
+@api_command
+class VoteBackdropCommand:
+    backdrop: str # one of ChangeGameViewEvent.backdropOptions
+
+@api_command
+class UndoStickerCommand:
+    pass # removes the last sticker the troll placed on the current painting
 
 @api_command
 class SetPaintingCommand:
@@ -178,6 +337,8 @@ class SetPaintingCommand:
 @api_event
 class EnterSessionEvent:
     sessionId: str 
+    joinSecret: str # secret required to join the session, empty if none is required
+    playerId: str # id of the local player in the PlayerInfo of the session
 
 @api_event
 class JoinSessionFailedEvent:
@@ -187,14 +348,43 @@ class JoinSessionFailedEvent:
 class KickedEvent:
     reason: str
 
+@api_event
+class SettingsChangedEvent:
+    settings: SessionSettings # the new settings of the session
+
+@api_event
+class PromptPacksEvent:
+    packs: list[PromptPackInfo] # all prompt packs the host can choose from
+
+@api_event
+class StickerPacksEvent:
+    packs: list[StickerPackInfo] # all sticker packs the host can choose from
+
+@api_event
+class BackdropPacksEvent:
+    packs: list[BackdropPackInfo] # all backdrop packs the host can choose from
+
+@api_event
+class SubmittedPromptsEvent:
+    prompts: list[str] # the custom prompts of the player that weren't used yet
+
+@api_event
+class InviteCreatedEvent:
+    invite: str # code for JoinSessionCommand.invite, contains session id and password
+
+@api_event
+class PublicSessionsEvent:
+    sessions: list[PublicSessionInfo] # public lobbies that can be joined, fullest first
+
 @api_struct
 class Painting:
     prompt: str # shows the current drawing prompt
     graphics: Graphics # the current painting data
-    backdrop: Backdrop # the ID of the backdrop 
+    backdrop: str # the id of the backdrop, see BackdropInfo
     stickers: list[Sticker] # the current list of stickers that should be shown
-    winner: bool # the painting is the winner
-    score: int # the number of total points (1...5 per player)
+    winner: bool # the painting has the best score, there may be several winners on a tie
+    score: float # average rating of the other players (1...5), 0 if nobody rated it
+    votes: int # number of players that rated the painting
 
 @api_event
 class ChangeGameViewEvent:
@@ -209,28 +399,98 @@ class ChangeGameViewEvent:
 
     announcer: str # the text shown on the announcer screen
 
+    canSkip: bool # the player may pass their turn with UserAction.skipTurn
+    canUndoSticker: bool # the player may remove their last sticker with an UndoStickerCommand
+    canGuess: bool # guessing mode: the troll may guess the prompt with a GuessCommand
+    canReact: bool # the player may react to the painting with a ReactCommand
+    backdropOptions: list[str] # promptselection: backdrops the troll may vote for with a VoteBackdropCommand
+
 @api_event
 class TimerChangedEvent:
     secondsLeft: int 
 
+@api_struct
+class ActiveEffect:
+    effect: Effect
+    parameters: dict[str, float] # intensity of the effect, depends on the effect
+
 @api_event
-class ChangeToolModifierEvent:
-    modifier: Effect
-    duration: int # milliseconds
+class ActiveEffectsChangedEvent:
+    effects: list[ActiveEffect] # all effects that are active right now, they stay active until the next change
 
 @api_event
 class PaintingChangedEvent:
     graphics: Graphics # the new painting
 
+@api_struct
+class PlayerInfo:
+    id: str # unique id of the player, never changes
+    name: str # nickname of the player
+    host: bool # the player is the host of the session
+    ready: bool # lobby: the player is ready to start
+    connected: bool # false for players that left during a match
+    bot: bool # the player is played by the server
+    score: float # points in the current or last match
+
 @api_event
 class PlayersChangedEvent:
-    players: list[str] # new list of present player
-    addedPlayer: None | str # player that joined
-    removedPlayer: None | str # player that left
+    players: list[PlayerInfo] # new list of present player, in join order
+    addedPlayer: None | PlayerInfo # player that joined
+    removedPlayer: None | PlayerInfo # player that left
 
 @api_event
 class PlayerReadyChangedEvent:
-    players: dict[str,bool] # contains a map from nick to "is ready"
+    players: list[PlayerInfo] # all players with their current ready state
+
+@api_event
+class ChatMessageEvent:
+    sender: PlayerInfo # player that wrote the message
+    message: str # the filtered message
+
+@api_event
+class ReactionEvent:
+    sender: PlayerInfo # player that reacted
+    reaction: Reaction
+
+@api_event
+class RatingProgressEvent:
+    votes: int # number of players that rated the shown painting so far
+    voters: int # number of players that may rate it
+
+@api_struct
+class LeaderboardEntry:
+    player: PlayerInfo
+    rank: int # 1 is the best, players with the same points share a rank
+    total: float # sum of all points below
+    painting: float # average rating of the player's painting
+    trolling: float # points for using troll effects
+    stickers: float # points for votes on the player's stickers
+    guessing: float # points for guessed prompts, painters get points when their prompt was guessed
+
+@api_struct
+class TeamEntry:
+    members: list[PlayerInfo] # the players that painted together
+    rank: int # 1 is the best, teams with the same points share a rank
+    total: float # average of the members' total points, so smaller teams aren't at a disadvantage
+
+@api_event
+class LeaderboardEvent:
+    entries: list[LeaderboardEntry] # all players of the match, best first
+    teams: list[TeamEntry] # team mode: all teams of the match, best first. Empty otherwise
+
+@api_struct
+class ScoreboardEntry:
+    player: PlayerInfo
+    rank: int # 1 is the best, players with the same wins and stars share a rank
+    matches: int # number of matches the player finished
+    wins: int # matches the player finished first on the leaderboard, ties count for all
+    stars: float # sum of the average ratings of all paintings of the player
+    bestPainting: None | Painting # the best rated painting of the player
+
+@api_event
+class ScoreboardEvent:
+    matches: int # matches played since the scoreboard was reset
+    entries: list[ScoreboardEntry] # all players in the session, best first
 
 @api_event
 class PopUpEvent:
@@ -592,6 +852,78 @@ table#status tr:nth-child(2) td {
 
         }
 
+        function handleInviteCreated(evt) {
+            log("Invite link: ", document.location.origin, "/?invite=", evt.invite);
+            return true;
+        }
+
+        function handlePublicSessions(evt) {
+            log("Public sessions:", evt.sessions.length ? "" : " none");
+            for(const info of evt.sessions) {
+                logButton(info.hostName + " (" + info.players + "/" + info.settings.maxPlayers + ")", function() {
+                    const nick = document.getElementById("JoinSessionCommand-arg-nickName").value;
+                    sendJoinSessionCommand(nick, info.sessionId, "", "", "");
+                });
+            }
+            return true;
+        }
+
+        function handleChatMessage(evt) {
+            log(evt.sender.name, ": ", evt.message);
+            return true;
+        }
+
+        function handleReaction(evt) {
+            log(evt.sender.name, " reacted with ", evt.reaction);
+            return true;
+        }
+
+        function handleSettingsChanged(evt) {
+            log("Settings: ", JSON.stringify(evt.settings));
+            return true;
+        }
+
+        function handlePromptPacks(evt) {
+            log("Prompt packs: ", evt.packs.map(p => p.id + " (" + p.language + ", " + p.rating + ")").join(", "));
+            return true;
+        }
+
+        function handleStickerPacks(evt) {
+            log("Sticker packs: ", evt.packs.map(p => p.id + " (" + p.stickers.length + " stickers)").join(", "));
+            return true;
+        }
+
+        function handleBackdropPacks(evt) {
+            log("Backdrop packs: ", evt.packs.map(p => p.id + " (" + p.backdrops.length + " backdrops)").join(", "));
+            return true;
+        }
+
+        function handleSubmittedPrompts(evt) {
+            log("Your prompts: ", evt.prompts.join(", ") || "-");
+            return true;
+        }
+
+        function handleRatingProgress(evt) {
+            log("Rating: ", evt.votes, " of ", evt.voters, " voted");
+            return true;
+        }
+
+        function handleLeaderboard(evt) {
+            log("Leaderboard:");
+            for(const entry of evt.entries) {
+                log("  ", entry.rank, ". ", entry.player.name, ": ", entry.total);
+            }
+            return true;
+        }
+
+        function handleScoreboard(evt) {
+            log("Scoreboard after ", evt.matches, " matches:");
+            for(const entry of evt.entries) {
+                log("  ", entry.rank, ". ", entry.player.name, ": ", entry.wins, " wins, ", entry.stars, " stars");
+            }
+            return true;
+        }
+
         function handleTimerChanged(evt) {
             setStatus("timer", evt.secondsLeft);
             return true;
@@ -604,7 +936,7 @@ table#status tr:nth-child(2) td {
 
         function handleChangeGameView(evt) {
             setStatus("view", evt.view);
-            setStatus("backdrop", evt.paintingBackdrop);
+            setStatus("backdrop", evt.painting.backdrop);
 
             log('ChangeGameViewEvent to ', JSON.stringify(evt.view));
             log('  painting: ', JSON.stringify(evt.painting));
@@ -640,8 +972,9 @@ table#status tr:nth-child(2) td {
             return true;
         }
 
-        function handleChangeToolModifier(evt) {
-
+        function handleActiveEffectsChanged(evt) {
+            log("Active effects: ", evt.effects.map(e => e.effect).join(", ") || "-");
+            return true;
         }
 
         function handlePaintingChanged(evt) {
@@ -649,16 +982,17 @@ table#status tr:nth-child(2) td {
         }
 
         function handlePlayersChanged(evt) {
-            setStatus("players", evt.players.join(", "));
+            const names = evt.players.map(p => p.name + (p.connected ? "" : " (offline)"));
+            setStatus("players", names.join(", "));
 
             if (evt.addedPlayer) {
-                log(evt.addedPlayer, " joined the game");
+                log(evt.addedPlayer.name, " joined the game");
             }
-            else if (evt.addedPlayer) {
-                log(evt.removedPlayer, " joined the game");
+            else if (evt.removedPlayer) {
+                log(evt.removedPlayer.name, " left the game");
             }
             else {
-                log("Players changed to: ", evt.players.join(", "));
+                log("Players changed to: ", names.join(", "));
             }
 
             return true;
@@ -667,9 +1001,9 @@ table#status tr:nth-child(2) td {
         function handlePlayerReadyChanged(evt) {
             log(
                 "READY: ", 
-                Object.keys(evt.players).filter(k => evt.players[k]).join(", ") || "-",
+                evt.players.filter(p => p.ready).map(p => p.name).join(", ") || "-",
                 "\tNOT READY:",
-                Object.keys(evt.players).filter(k => !evt.players[k]).join(", " || "-")
+                evt.players.filter(p => !p.ready).map(p => p.name).join(", ") || "-"
             )
             return true;
         }
@@ -683,9 +1017,13 @@ table#status tr:nth-child(2) td {
             lineout("{")
 
             for field, hint in typing.get_type_hints(atype.pytype).items():
+                if hint == bool:
+                    lineout("    let ", field, ' = document.getElementById("', f"{atype.name}-arg-{field}", '").checked;')
+                    continue
+
                 lineout("    let ", field, ' = document.getElementById("', f"{atype.name}-arg-{field}", '").value;')
                 
-                if hint == float:
+                if hint == float or hint == int:
                     lineout("    ", field, " = Number(", field, ");")
                 elif hint == str:
                     pass
@@ -693,6 +1031,8 @@ table#status tr:nth-child(2) td {
                     pass 
                 elif issubclass(hint, Enum):
                     pass  # enums are strings
+                elif type_registry.get(hint.__name__, None) and type_registry[hint.__name__].dir == ApiDirection.struct:
+                    lineout("    ", field, " = JSON.parse(", field, ");")
                 else:
                     print("Unsupported command type:", hint)
                     exit(1)
@@ -765,6 +1105,7 @@ table#status tr:nth-child(2) td {
 
             document.getElementById("CreateSessionCommand-arg-nickName").value = nick;
             document.getElementById("JoinSessionCommand-arg-nickName").value = nick;
+            document.getElementById("QuickMatchCommand-arg-nickName").value = nick;
             document.getElementById('JoinSessionCommand-arg-sessionId').value = "0xDEADBEEF";
 
             reconnect();
@@ -799,9 +1140,12 @@ table#status tr:nth-child(2) td {
                 lineout("<span>", field, ":</span>")
 
                 js_type = "text"
-                if hint == float:
+                if hint == float or hint == int:
                     js_type = "number"
 
+                elif hint == bool:
+                    js_type = "checkbox"
+
                 elif issubclass(hint, Enum):
                     lineout('<select id="', f"{atype.name}-arg-{field}" ,'">')
                     for item in hint: